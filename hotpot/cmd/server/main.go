@@ -5,7 +5,9 @@ import (
 	"hotpot/internal/core/utils/logger"
 	"hotpot/internal/core/utils/servers"
 	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/core/utils/servers/scheduler"
 	"hotpot/internal/pkg"
+	"os"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,9 +21,16 @@ func main() {
 
 	appLogger := logger.New(logger.DefaultConfig())
 
-	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+		BodyLimit:             appCfg.HTTPBodyLimit,
+	})
 
-	appRouter := pkg.NewRouter(appLogger)
+	appRouter, err := pkg.NewRouter(appLogger, appCfg)
+	if err != nil {
+		appLogger.Error("startup failed", "error", err)
+		os.Exit(1)
+	}
 
 	servMan := servers.NewServerManager()
 
@@ -29,6 +38,10 @@ func main() {
 
 	servMan.AddServer(httpServ)
 
+	jobs := scheduler.New(appLogger)
+	appRouter.InitJobs(jobs)
+	servMan.AddServer(jobs)
+
 	servMan.StartAll()
 
 	defer servMan.StopAll()
@@ -3,13 +3,126 @@ package cfg
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	env "github.com/joho/godotenv"
 )
 
+// SigningKey is a named HMAC key used to sign and verify JWTs.
+type SigningKey struct {
+	ID     string
+	Secret []byte
+}
+
+// OAuthProviderConfig describes the endpoints and client credentials of an
+// OAuth2/OIDC identity provider. A provider without a ClientID is disabled.
+type OAuthProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	AuthURL      string
+	TokenURL     string
+	// UserInfoURL is queried for the identity when JWKSURL is empty.
+	UserInfoURL string
+	// JWKSURL and Issuer are used to verify the ID token returned by the provider.
+	JWKSURL string
+	Issuer  string
+	Scopes  []string
+	// ResponseMode is passed as response_mode, e.g. "form_post" for Apple.
+	ResponseMode string
+}
+
 type Config struct {
+	// DevMode allows built-in development secrets in place of required
+	// ones. It must stay off in production.
+	DevMode  bool
 	HttpPort string
+	// HTTPBodyLimit is the largest request body accepted, in bytes.
+	HTTPBodyLimit int
+	// AppBaseURL is the address of the client app, used to build links in emails.
+	AppBaseURL string
+
+	// JWTSigningKeys holds every key accepted when verifying tokens.
+	// The first key is used to sign new tokens, the rest allow rotation.
+	JWTSigningKeys  []SigningKey
+	JWTIssuer       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// AdminEmails are granted the admin role once they verify their address.
+	AdminEmails []string
+
+	// TOTPIssuer is the account label shown in authenticator apps.
+	TOTPIssuer string
+
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+
+	// RateLimitRedisURL points rate limiters at a shared Redis-compatible
+	// server. When empty, limits are kept per instance in memory.
+	RateLimitRedisURL string
+	// AuthIPLimit requests per AuthIPWindow are allowed per client IP on
+	// login and password reset endpoints.
+	AuthIPLimit  int
+	AuthIPWindow time.Duration
+	// After LockoutThreshold failed logins within LockoutWindow the account
+	// is locked for LockoutBase, doubling with every further failure up to LockoutMax.
+	LockoutThreshold int
+	LockoutBase      time.Duration
+	LockoutMax       time.Duration
+	LockoutWindow    time.Duration
+	// PasswordResetLimit reset emails per PasswordResetWindow are sent per address.
+	PasswordResetLimit  int
+	PasswordResetWindow time.Duration
+
+	// AccountDeletionGrace is how long a user can cancel the deletion of their
+	// account. Due deletions are carried out every AccountDeletionInterval.
+	AccountDeletionGrace    time.Duration
+	AccountDeletionInterval time.Duration
+
+	// Adaptive diets are recalibrated every DietRecalibrationPeriod; due
+	// diets are looked for every DietRecalibrationInterval. A recalibration
+	// moves the daily energy by at most DietMaxAdjustmentKcal and keeps it
+	// between DietMinKcal and DietMaxKcal.
+	DietRecalibrationPeriod   time.Duration
+	DietRecalibrationInterval time.Duration
+	DietMaxAdjustmentKcal     int
+	DietMinKcal               int
+	DietMaxKcal               int
+
+	// BlobStoreType selects where uploaded files are kept: "local" or "s3".
+	BlobStoreType string
+	// BlobDir, BlobPublicURL and BlobURLSecret configure the local store.
+	// BlobPublicURL must point at the route serving the files.
+	BlobDir       string
+	BlobPublicURL string
+	BlobURLSecret []byte
+	S3Endpoint    string
+	S3Region      string
+	S3Bucket      string
+	S3AccessKey   string
+	S3SecretKey   string
+	S3PathStyle   bool
+
+	// AvatarMaxBytes bounds uploaded profile pictures. Links to them are
+	// valid for AvatarURLTTL.
+	AvatarMaxBytes int
+	AvatarURLTTL   time.Duration
+
+	// MailerType selects the mailer: "smtp", "log" or "file".
+	MailerType   string
+	MailFrom     string
+	MailerDir    string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// OAuthProviders maps provider names used in /auth/oauth/:provider routes to their settings.
+	OAuthProviders map[string]OAuthProviderConfig
 }
 
 var (
@@ -25,8 +138,89 @@ func Inst() *Config {
 			log.Printf("Warning: Could not load .env file, falling back to system environment variables")
 		}
 
+		devMode := getEnvBool("DEV_MODE", false)
+		blobStore := getEnv("BLOB_STORE", "local")
+		var blobURLSecret []byte
+		// Only the local store signs its URLs itself.
+		if blobStore == "local" {
+			blobURLSecret = getEnvSecret("BLOB_URL_SECRET", "insecure-development-secret", devMode)
+		}
 		instance = &Config{
-			HttpPort: getEnv("HTTP_PORT", "8080"),
+			DevMode:       devMode,
+			HttpPort:      getEnv("HTTP_PORT", "8080"),
+			HTTPBodyLimit: getEnvInt("HTTP_BODY_LIMIT", 10<<20),
+			AppBaseURL:    getEnv("APP_BASE_URL", "http://localhost:3000"),
+
+			JWTSigningKeys:  getEnvSigningKeys("JWT_SIGNING_KEYS", "dev:insecure-development-key", devMode),
+			JWTIssuer:       getEnv("JWT_ISSUER", "hotpot"),
+			AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+			AdminEmails: getEnvList("ADMIN_EMAILS"),
+
+			TOTPIssuer: getEnv("TOTP_ISSUER", "HotPot"),
+
+			PasswordResetTTL:     getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+			EmailVerificationTTL: getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+
+			RateLimitRedisURL:   getEnv("RATE_LIMIT_REDIS_URL", ""),
+			AuthIPLimit:         getEnvInt("AUTH_IP_LIMIT", 20),
+			AuthIPWindow:        getEnvDuration("AUTH_IP_WINDOW", time.Minute),
+			LockoutThreshold:    getEnvInt("LOCKOUT_THRESHOLD", 5),
+			LockoutBase:         getEnvDuration("LOCKOUT_BASE", time.Minute),
+			LockoutMax:          getEnvDuration("LOCKOUT_MAX", time.Hour),
+			LockoutWindow:       getEnvDuration("LOCKOUT_WINDOW", 24*time.Hour),
+			PasswordResetLimit:  getEnvInt("PASSWORD_RESET_LIMIT", 3),
+			PasswordResetWindow: getEnvDuration("PASSWORD_RESET_WINDOW", time.Hour),
+
+			AccountDeletionGrace:    getEnvDuration("ACCOUNT_DELETION_GRACE", 30*24*time.Hour),
+			AccountDeletionInterval: getEnvDuration("ACCOUNT_DELETION_INTERVAL", time.Hour),
+
+			DietRecalibrationPeriod:   getEnvDuration("DIET_RECALIBRATION_PERIOD", 7*24*time.Hour),
+			DietRecalibrationInterval: getEnvDuration("DIET_RECALIBRATION_INTERVAL", time.Hour),
+			DietMaxAdjustmentKcal:     getEnvInt("DIET_MAX_ADJUSTMENT_KCAL", 200),
+			DietMinKcal:               getEnvInt("DIET_MIN_KCAL", 1200),
+			DietMaxKcal:               getEnvInt("DIET_MAX_KCAL", 5000),
+
+			BlobStoreType: blobStore,
+			BlobDir:       getEnv("BLOB_DIR", "./tmp/blobs"),
+			BlobPublicURL: getEnv("BLOB_PUBLIC_URL", "http://localhost:8080/user-module/api/v1/user/files"),
+			BlobURLSecret: blobURLSecret,
+			S3Endpoint:    getEnv("S3_ENDPOINT", ""),
+			S3Region:      getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:      getEnv("S3_BUCKET", ""),
+			S3AccessKey:   getEnv("S3_ACCESS_KEY_ID", ""),
+			S3SecretKey:   getEnv("S3_SECRET_ACCESS_KEY", ""),
+			S3PathStyle:   getEnvBool("S3_PATH_STYLE", true),
+
+			AvatarMaxBytes: getEnvInt("AVATAR_MAX_BYTES", 8<<20),
+			AvatarURLTTL:   getEnvDuration("AVATAR_URL_TTL", time.Hour),
+
+			MailerType:   getEnv("MAILER", "log"),
+			MailFrom:     getEnv("MAIL_FROM", "HotPot <no-reply@hotpot.local>"),
+			MailerDir:    getEnv("MAILER_DIR", "./tmp/mail"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+			OAuthProviders: map[string]OAuthProviderConfig{
+				"google": getEnvOAuthProvider("OAUTH_GOOGLE", OAuthProviderConfig{
+					AuthURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+					TokenURL: "https://oauth2.googleapis.com/token",
+					JWKSURL:  "https://www.googleapis.com/oauth2/v3/certs",
+					Issuer:   "https://accounts.google.com",
+					Scopes:   []string{"openid", "email", "profile"},
+				}),
+				"apple": getEnvOAuthProvider("OAUTH_APPLE", OAuthProviderConfig{
+					AuthURL:      "https://appleid.apple.com/auth/authorize",
+					TokenURL:     "https://appleid.apple.com/auth/token",
+					JWKSURL:      "https://appleid.apple.com/auth/keys",
+					Issuer:       "https://appleid.apple.com",
+					Scopes:       []string{"openid", "email", "name"},
+					ResponseMode: "form_post",
+				}),
+			},
 		}
 	})
 	return instance
@@ -38,3 +232,115 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList parses a comma-separated list, lowercasing and trimming every item.
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: Invalid integer %q for %s, using %d", value, key, defaultValue)
+		return defaultValue
+	}
+	return i
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: Invalid boolean %q for %s, using %t", value, key, defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: Invalid duration %q for %s, using %s", value, key, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// getEnvSecret reads a secret that is required unless devMode allows the
+// insecure default.
+func getEnvSecret(key, defaultValue string, devMode bool) []byte {
+	if value, exists := os.LookupEnv(key); exists && value != "" {
+		return []byte(value)
+	}
+	if !devMode {
+		log.Fatalf("%s is not set; set DEV_MODE=true to use an insecure development secret", key)
+	}
+	log.Printf("Warning: %s is not set, using an insecure development secret", key)
+	return []byte(defaultValue)
+}
+
+// getEnvSigningKeys parses a comma-separated list of "id:secret" pairs. The
+// keys are required unless devMode allows the insecure default.
+func getEnvSigningKeys(key, defaultValue string, devMode bool) []SigningKey {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		if !devMode {
+			log.Fatalf("%s is not set; set DEV_MODE=true to use an insecure development key", key)
+		}
+		log.Printf("Warning: %s is not set, using an insecure development key", key)
+		value = defaultValue
+	}
+
+	var keys []SigningKey
+	for _, pair := range strings.Split(value, ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || id == "" || secret == "" {
+			log.Printf("Warning: Skipping malformed signing key in %s", key)
+			continue
+		}
+		keys = append(keys, SigningKey{ID: id, Secret: []byte(secret)})
+	}
+	if len(keys) == 0 {
+		log.Fatalf("No valid signing keys configured in %s", key)
+	}
+	return keys
+}
+
+// getEnvOAuthProvider reads <prefix>_CLIENT_ID, <prefix>_AUTH_URL and friends,
+// falling back to the provider's public endpoints.
+func getEnvOAuthProvider(prefix string, defaults OAuthProviderConfig) OAuthProviderConfig {
+	scopes := defaults.Scopes
+	if value, exists := os.LookupEnv(prefix + "_SCOPES"); exists {
+		scopes = strings.Fields(value)
+	}
+
+	return OAuthProviderConfig{
+		ClientID:     getEnv(prefix+"_CLIENT_ID", ""),
+		ClientSecret: getEnv(prefix+"_CLIENT_SECRET", ""),
+		RedirectURL:  getEnv(prefix+"_REDIRECT_URL", ""),
+		AuthURL:      getEnv(prefix+"_AUTH_URL", defaults.AuthURL),
+		TokenURL:     getEnv(prefix+"_TOKEN_URL", defaults.TokenURL),
+		UserInfoURL:  getEnv(prefix+"_USERINFO_URL", defaults.UserInfoURL),
+		JWKSURL:      getEnv(prefix+"_JWKS_URL", defaults.JWKSURL),
+		Issuer:       getEnv(prefix+"_ISSUER", defaults.Issuer),
+		Scopes:       scopes,
+		ResponseMode: getEnv(prefix+"_RESPONSE_MODE", defaults.ResponseMode),
+	}
+}
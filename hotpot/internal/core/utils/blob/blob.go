@@ -0,0 +1,84 @@
+// Package blob stores binary objects such as pictures, on local disk for
+// development or in an S3-compatible bucket in production, and hands out
+// time-limited signed URLs to read them.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore defines the interface for storing and serving blobs. Keys are
+// slash-separated paths such as "avatars/<user>/256.jpg".
+type BlobStore interface {
+	// Put stores data under key, replacing any previous blob.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the blob stored under key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	// Delete removes the blob. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that reads the blob without credentials until ttl has passed.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Type represents the kind of blob store to create.
+type Type string
+
+const (
+	Local Type = "local" // Files in a directory, served by the app itself.
+	S3    Type = "s3"    // An S3-compatible bucket such as AWS S3 or MinIO.
+)
+
+// Config holds the settings for every store type; only the fields of the
+// selected type are used.
+type Config struct {
+	Type Type
+
+	// Dir is where the local store keeps its files. PublicURL is the address
+	// under which the app serves them, see LocalStore.Handler, and URLSecret
+	// signs their URLs.
+	Dir       string
+	PublicURL string
+	URLSecret []byte
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	// S3PathStyle addresses the bucket as endpoint/bucket instead of
+	// bucket.endpoint, as MinIO and most self-hosted servers expect.
+	S3PathStyle bool
+}
+
+// New creates a BlobStore of the configured type.
+//
+// Arguments:
+//
+//	config - The store settings.
+//
+// Returns:
+//
+//	A BlobStore implementation, or an error if the type is unsupported or
+//	its settings are incomplete.
+func New(config Config) (BlobStore, error) {
+	switch config.Type {
+	case Local:
+		return NewLocalStore(config.Dir, config.PublicURL, config.URLSecret)
+	case S3:
+		return NewS3Store(S3Config{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+			PathStyle: config.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported blob store type: %s", config.Type)
+	}
+}
@@ -0,0 +1,154 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStore keeps blobs as files in a directory. The content type is derived
+// from the key's extension. Signed URLs point at PublicURL, where the app
+// serves the files with Handler.
+type LocalStore struct {
+	dir       string
+	publicURL string
+	secret    []byte
+}
+
+func NewLocalStore(dir, publicURL string, secret []byte) (*LocalStore, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("local blob store needs a URL signing secret")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{
+		dir:       dir,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		secret:    secret,
+	}, nil
+}
+
+func (s *LocalStore) Put(_ context.Context, key string, data []byte, _ string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial blob.
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *LocalStore) Get(_ context.Context, key string) ([]byte, string, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", err
+	}
+	return data, contentType(key), nil
+}
+
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", s.sign(key, expires))
+	return s.publicURL + "/" + key + "?" + q.Encode(), nil
+}
+
+// Handler serves blobs at the store's PublicURL, which must be mounted with a
+// trailing wildcard, e.g. router.Get("/files/*", store.Handler()). Requests
+// without a valid, unexpired signature are rejected.
+func (s *LocalStore) Handler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Params("*")
+		expires := ctx.Query("expires")
+		exp, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || time.Now().Unix() > exp ||
+			!hmac.Equal([]byte(ctx.Query("signature")), []byte(s.sign(key, expires))) {
+			return http.NewResponse(ctx, http.Forbidden, nil, http.CodeInvalidToken, "Invalid or expired link")
+		}
+
+		data, typ, err := s.Get(ctx.Context(), key)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, "Not found")
+			}
+			return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+		}
+		ctx.Set(fiber.HeaderContentType, typ)
+		ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", max(exp-time.Now().Unix(), 0)))
+		return ctx.Send(data)
+	}
+}
+
+func (s *LocalStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path maps a key to a file in the store's directory, refusing keys that
+// would escape it.
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || path.Clean(key) != key || strings.HasPrefix(key, "/") || strings.HasPrefix(key, "..") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func contentType(key string) string {
+	if t := mime.TypeByExtension(path.Ext(key)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,171 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newTestLocalStore(t *testing.T) *LocalStore {
+	t.Helper()
+	store, err := NewLocalStore(t.TempDir(), "http://localhost/files", []byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestNewLocalStoreNeedsSecret(t *testing.T) {
+	if _, err := NewLocalStore(t.TempDir(), "http://localhost/files", nil); err == nil {
+		t.Fatal("store created without a URL signing secret")
+	}
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newTestLocalStore(t)
+
+	if err := store.Put(ctx, "avatars/u1/256.png", []byte("png"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	data, typ, err := store.Get(ctx, "avatars/u1/256.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "png" || typ != "image/png" {
+		t.Fatalf("Get = %q, %q", data, typ)
+	}
+
+	if err := store.Delete(ctx, "avatars/u1/256.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Get(ctx, "avatars/u1/256.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "avatars/u1/256.png"); err != nil {
+		t.Fatalf("deleting a missing blob: %v", err)
+	}
+}
+
+func TestLocalStoreRejectsKeysOutsideDir(t *testing.T) {
+	ctx := context.Background()
+	parent := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(parent, "blobs"), "http://localhost/files", []byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{
+		"",
+		"../escape.txt",
+		"..",
+		"/etc/passwd",
+		"avatars/../../escape.txt",
+		"avatars/./256.png",
+		"avatars//256.png",
+		"avatars/",
+	}
+	for _, key := range keys {
+		if err := store.Put(ctx, key, []byte("x"), "text/plain"); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+		if _, _, err := store.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): err = %v, want an invalid key", key, err)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) succeeded", key)
+		}
+		if _, err := store.SignedURL(ctx, key, time.Minute); err == nil {
+			t.Errorf("SignedURL(%q) succeeded", key)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(parent, "escape.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("a blob was written outside the store: %v", err)
+	}
+}
+
+func TestLocalStoreSignedURL(t *testing.T) {
+	ctx := context.Background()
+	store := newTestLocalStore(t)
+	if err := store.Put(ctx, "avatars/u1/256.png", []byte("png"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New()
+	app.Get("/files/*", store.Handler())
+
+	get := func(t *testing.T, rawURL string) (int, string) {
+		t.Helper()
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := app.Test(httptest.NewRequest("GET", u.RequestURI(), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	signed, err := store.SignedURL(ctx, "avatars/u1/256.png", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, body := get(t, signed); status != fiber.StatusOK || body != "png" {
+		t.Fatalf("signed URL: status %d, body %q", status, body)
+	}
+
+	u, _ := url.Parse(signed)
+	q := u.Query()
+
+	t.Run("other key", func(t *testing.T) {
+		other := *u
+		other.Path = "/files/avatars/u2/256.png"
+		if status, _ := get(t, other.String()); status != fiber.StatusForbidden {
+			t.Fatalf("status %d, want 403", status)
+		}
+	})
+	t.Run("tampered signature", func(t *testing.T) {
+		tampered := *u
+		sig := []byte(q.Get("signature"))
+		if sig[0] == 'a' {
+			sig[0] = 'b'
+		} else {
+			sig[0] = 'a'
+		}
+		tq := url.Values{"expires": q["expires"], "signature": {string(sig)}}
+		tampered.RawQuery = tq.Encode()
+		if status, _ := get(t, tampered.String()); status != fiber.StatusForbidden {
+			t.Fatalf("status %d, want 403", status)
+		}
+	})
+	t.Run("extended expiry", func(t *testing.T) {
+		extended := *u
+		exp, _ := strconv.ParseInt(q.Get("expires"), 10, 64)
+		eq := url.Values{"expires": {strconv.FormatInt(exp+3600, 10)}, "signature": q["signature"]}
+		extended.RawQuery = eq.Encode()
+		if status, _ := get(t, extended.String()); status != fiber.StatusForbidden {
+			t.Fatalf("status %d, want 403", status)
+		}
+	})
+	t.Run("expired", func(t *testing.T) {
+		expired, err := store.SignedURL(ctx, "avatars/u1/256.png", -time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status, _ := get(t, expired); status != fiber.StatusForbidden {
+			t.Fatalf("status %d, want 403", status)
+		}
+	})
+}
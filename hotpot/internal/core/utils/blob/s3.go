@@ -0,0 +1,283 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4DateLayout  = "20060102T150405Z"
+	sigV4ScopeLayout = "20060102"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	// maxPresignTTL is the longest validity S3 accepts for presigned URLs.
+	maxPresignTTL = 7 * 24 * time.Hour
+	s3Timeout     = 30 * time.Second
+)
+
+// S3Config holds the settings of an S3-compatible bucket.
+type S3Config struct {
+	// Endpoint is e.g. "http://localhost:9000" for MinIO. It defaults to the
+	// AWS endpoint of the region.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+// S3Store keeps blobs in an S3-compatible bucket. Requests are signed with
+// AWS Signature Version 4, and signed URLs are presigned GET requests, so
+// clients download straight from the bucket.
+type S3Store struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("s3 blob store needs a bucket and credentials")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+	}
+
+	return &S3Store{
+		cfg:      config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: s3Timeout},
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	res, err := s.do(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkS3Response(res, http.StatusOK)
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, string, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, "", ErrNotFound
+	}
+	if err := checkS3Response(res, http.StatusOK); err != nil {
+		return nil, "", err
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, res.Header.Get("Content-Type"), nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkS3Response(res, http.StatusNoContent, http.StatusOK)
+}
+
+func (s *S3Store) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	return s.presign(key, min(ttl, maxPresignTTL), time.Now().UTC()), nil
+}
+
+// presign returns a GET URL for the object signed at the given time.
+func (s *S3Store) presign(key string, ttl time.Duration, now time.Time) string {
+	u := s.objectURL(key)
+
+	q := url.Values{}
+	q.Set("X-Amz-Algorithm", sigV4Algorithm)
+	q.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+s.scope(now))
+	q.Set("X-Amz-Date", now.Format(sigV4DateLayout))
+	q.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	q.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	q.Set("X-Amz-Signature", s.signature(now, canonical))
+
+	u.RawQuery = canonicalQuery(q)
+	return u.String()
+}
+
+// do sends a request for the object signed in the Authorization header.
+func (s *S3Store) do(ctx context.Context, method, key string, body []byte, contentType string) (*http.Response, error) {
+	u := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	payloadHash := sha256Hex(body)
+	headers := map[string]string{
+		"host":                 u.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           now.Format(sigV4DateLayout),
+	}
+	if contentType != "" {
+		headers["content-type"] = contentType
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+		if name != "host" {
+			req.Header.Set(name, headers[name])
+		}
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.cfg.AccessKey, s.scope(now), signedHeaders, s.signature(now, canonical)))
+
+	return s.client.Do(req)
+}
+
+// objectURL returns the address of the object, with each key segment escaped
+// the way SigV4 expects in canonical paths.
+func (s *S3Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = sigV4Escape(seg)
+	}
+	escaped := strings.Join(segments, "/")
+
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.cfg.PathStyle {
+		u.RawPath = base + "/" + sigV4Escape(s.cfg.Bucket) + "/" + escaped
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.RawPath = base + "/" + escaped
+	}
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	return &u
+}
+
+func (s *S3Store) scope(now time.Time) string {
+	return now.Format(sigV4ScopeLayout) + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+// signature signs a canonical request with the key derived for the day,
+// region and service.
+func (s *S3Store) signature(now time.Time, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		now.Format(sigV4DateLayout),
+		s.scope(now),
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), now.Format(sigV4ScopeLayout))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func checkS3Response(res *http.Response, expected ...int) error {
+	for _, code := range expected {
+		if res.StatusCode == code {
+			return nil
+		}
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("s3 request failed with status %d: %s", res.StatusCode, bytes.TrimSpace(msg))
+}
+
+// canonicalQuery encodes the parameters sorted by name, escaped as SigV4 requires.
+func canonicalQuery(q url.Values) string {
+	names := make([]string, 0, len(q))
+	for name := range q {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, v := range q[name] {
+			parts = append(parts, sigV4Escape(name)+"="+sigV4Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// sigV4Escape percent-encodes everything except unreserved characters.
+func sigV4Escape(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,66 @@
+// Package mailer provides an abstraction for sending transactional emails,
+// with SMTP delivery for production and log/file sinks for local development.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string // Recipient address.
+	Subject string // Subject line.
+	Body    string // Plain-text body.
+}
+
+// Mailer defines the interface for delivering emails.
+type Mailer interface {
+	// Send delivers the message or returns an error if it could not be handed off.
+	Send(ctx context.Context, msg Message) error
+}
+
+// Type represents the kind of mailer to create.
+type Type string
+
+const (
+	SMTP Type = "smtp" // Deliver through an SMTP relay.
+	Log  Type = "log"  // Write messages to the application log.
+	File Type = "file" // Write messages as .eml files into a directory.
+)
+
+// Config holds the settings for every mailer type; only the fields of the
+// selected type are used.
+type Config struct {
+	Type         Type
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	Dir          string
+}
+
+// New creates a Mailer of the configured type.
+//
+// Arguments:
+//
+//	config - The mailer settings.
+//	logger - The logger used by the log mailer.
+//
+// Returns:
+//
+//	A Mailer implementation, or an error if the type is unsupported.
+func New(config Config, logger *slog.Logger) (Mailer, error) {
+	switch config.Type {
+	case SMTP:
+		return NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.From), nil
+	case Log:
+		return NewLogMailer(logger), nil
+	case File:
+		return NewFileMailer(config.Dir, config.From), nil
+	default:
+		return nil, fmt.Errorf("unsupported mailer type: %s", config.Type)
+	}
+}
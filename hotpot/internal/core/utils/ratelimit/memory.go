@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often MemoryStore drops keys that are no longer hit.
+const sweepInterval = time.Minute
+
+// MemoryStore is a Store kept in process memory. Limits are not shared
+// between instances; use RedisStore for that.
+type MemoryStore struct {
+	mu   sync.Mutex
+	hits map[string][]time.Time
+	// windows holds the window each key was last used with, so that the
+	// sweep knows when its hits expire.
+	windows   map[string]time.Duration
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		hits:    make(map[string][]time.Time),
+		windows: make(map[string]time.Duration),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit int, window time.Duration, now time.Time) (Window, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hits := s.prune(key, window, now)
+	ok := len(hits) < limit
+	if ok {
+		hits = append(hits, now)
+		s.hits[key] = hits
+		s.windows[key] = window
+	}
+	return summarize(hits), ok, nil
+}
+
+func (s *MemoryStore) Peek(_ context.Context, key string, window time.Duration, now time.Time) (Window, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return summarize(s.prune(key, window, now)), nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.hits, key)
+	delete(s.windows, key)
+	return nil
+}
+
+// prune drops hits that fell out of the window. Hits are kept in time order.
+func (s *MemoryStore) prune(key string, window time.Duration, now time.Time) []time.Time {
+	s.sweep(now)
+
+	hits := s.hits[key]
+	cutoff := now.Add(-window)
+
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	hits = hits[i:]
+
+	if len(hits) == 0 {
+		delete(s.hits, key)
+		delete(s.windows, key)
+		return nil
+	}
+	s.hits[key] = hits
+	s.windows[key] = window
+	return hits
+}
+
+// sweep drops the keys whose hits have all expired, at most once per
+// sweepInterval. Without it, keys that are never hit again, such as the IPs
+// of past clients, would stay in memory for good.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, hits := range s.hits {
+		if !hits[len(hits)-1].After(now.Add(-s.windows[key])) {
+			delete(s.hits, key)
+			delete(s.windows, key)
+		}
+	}
+}
+
+func summarize(hits []time.Time) Window {
+	if len(hits) == 0 {
+		return Window{}
+	}
+	return Window{Count: len(hits), Oldest: hits[0], Newest: hits[len(hits)-1]}
+}
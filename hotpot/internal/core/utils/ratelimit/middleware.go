@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"hotpot/internal/core/utils/servers/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware limits requests per key, answering with 429 and a Retry-After
+// header once the limit is reached. Store failures let the request through.
+//
+// Arguments:
+//
+//	limiter - The limiter to apply.
+//	key - Extracts the rate limit key from the request, e.g. the client IP.
+func Middleware(limiter *Limiter, key func(ctx *fiber.Ctx) string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		err := limiter.Allow(ctx.Context(), key(ctx))
+		var exceeded *ExceededError
+		if errors.As(err, &exceeded) {
+			return Reject(ctx, exceeded, http.CodeTooManyRequests)
+		}
+		return ctx.Next()
+	}
+}
+
+// Reject answers with 429 Too Many Requests and a Retry-After header.
+func Reject(ctx *fiber.Ctx, err *ExceededError, code http.CustomCode) error {
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+	return http.NewResponse(ctx, http.TooManyRequests, nil, code, err.Error())
+}
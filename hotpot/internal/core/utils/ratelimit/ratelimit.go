@@ -0,0 +1,147 @@
+// Package ratelimit provides sliding-window rate limiting and progressive
+// lockouts on top of a pluggable Store, so limits can be shared by every
+// instance of the application when a networked store is used.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Window summarises the hits recorded for a key within a sliding window.
+type Window struct {
+	Count  int       // Number of hits within the window.
+	Oldest time.Time // Time of the oldest hit within the window.
+	Newest time.Time // Time of the newest hit within the window.
+}
+
+// Store records timestamped hits per key.
+type Store interface {
+	// Take records a hit only if fewer than limit hits fall within the window
+	// ending at now. It reports whether the hit was recorded.
+	Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (Window, bool, error)
+	// Peek returns the hits within the window without recording one.
+	Peek(ctx context.Context, key string, window time.Duration, now time.Time) (Window, error)
+	// Reset forgets every hit of the key.
+	Reset(ctx context.Context, key string) error
+}
+
+// ExceededError is returned when a limit is hit or a key is locked out.
+type ExceededError struct {
+	RetryAfter time.Duration // How long the caller should wait before retrying.
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("too many attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// Limiter allows at most Limit hits per key within a sliding Window.
+type Limiter struct {
+	store  Store
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// New creates a Limiter. The prefix namespaces its keys in the store.
+func New(store Store, prefix string, limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		store:  store,
+		prefix: prefix,
+		limit:  limit,
+		window: window,
+	}
+}
+
+// Allow records a hit for key, or returns an ExceededError if the limit is reached.
+func (l *Limiter) Allow(ctx context.Context, key string) error {
+	now := time.Now()
+	w, ok, err := l.store.Take(ctx, l.prefix+":"+key, l.limit, l.window, now)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &ExceededError{RetryAfter: w.Oldest.Add(l.window).Sub(now)}
+	}
+	return nil
+}
+
+// Lockout locks a key out after repeated failures. Once Threshold failures
+// fall within Window, every further failure doubles the lock, starting at
+// Base and capped at Max.
+type Lockout struct {
+	store     Store
+	prefix    string
+	threshold int
+	base      time.Duration
+	max       time.Duration
+	window    time.Duration
+}
+
+// NewLockout creates a Lockout. The prefix namespaces its keys in the store.
+func NewLockout(store Store, prefix string, threshold int, base, max, window time.Duration) *Lockout {
+	return &Lockout{
+		store:     store,
+		prefix:    prefix,
+		threshold: threshold,
+		base:      base,
+		max:       max,
+		window:    window,
+	}
+}
+
+// Check returns an ExceededError if the key is currently locked out.
+func (l *Lockout) Check(ctx context.Context, key string) error {
+	now := time.Now()
+	w, err := l.store.Peek(ctx, l.prefix+":"+key, l.window, now)
+	if err != nil {
+		return err
+	}
+	return l.lockedUntil(w, now)
+}
+
+// Fail records a failure and returns an ExceededError if it locked the key out.
+func (l *Lockout) Fail(ctx context.Context, key string) error {
+	now := time.Now()
+	w, _, err := l.store.Take(ctx, l.prefix+":"+key, math.MaxInt32, l.window, now)
+	if err != nil {
+		return err
+	}
+	return l.lockedUntil(w, now)
+}
+
+// Reset clears the failures of the key, e.g. after a successful login.
+func (l *Lockout) Reset(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, l.prefix+":"+key)
+}
+
+func (l *Lockout) lockedUntil(w Window, now time.Time) error {
+	if w.Count < l.threshold {
+		return nil
+	}
+
+	d := l.max
+	if shift := w.Count - l.threshold; shift < 32 {
+		d = min(l.base<<shift, l.max)
+	}
+	if until := w.Newest.Add(d); until.After(now) {
+		return &ExceededError{RetryAfter: until.Sub(now)}
+	}
+	return nil
+}
+
+// NewStore returns a RedisStore when redisURL is set and a MemoryStore otherwise.
+func NewStore(redisURL string) (Store, error) {
+	if redisURL == "" {
+		return NewMemoryStore(), nil
+	}
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+	return NewRedisStore(redis.NewClient(opts)), nil
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript keeps each key as a sorted set of hit timestamps (in ms) and
+// atomically prunes, checks and records a hit.
+//
+// KEYS[1] - the key; ARGV: now, window, limit, unique member, peek flag.
+var takeScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local ok = 0
+if ARGV[5] == '0' and count < tonumber(ARGV[3]) then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	count = count + 1
+	ok = 1
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+return {ok, count, oldest[2] or '0', newest[2] or '0'}
+`)
+
+// RedisStore is a Store backed by Redis or any server speaking its protocol,
+// so every instance of the application shares the same limits.
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (Window, bool, error) {
+	return s.run(ctx, key, limit, window, now, false)
+}
+
+func (s *RedisStore) Peek(ctx context.Context, key string, window time.Duration, now time.Time) (Window, error) {
+	w, _, err := s.run(ctx, key, 0, window, now, true)
+	return w, err
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+func (s *RedisStore) run(ctx context.Context, key string, limit int, window time.Duration, now time.Time, peek bool) (Window, bool, error) {
+	member := make([]byte, 8)
+	if _, err := rand.Read(member); err != nil {
+		return Window{}, false, err
+	}
+	peekFlag := "0"
+	if peek {
+		peekFlag = "1"
+	}
+
+	res, err := takeScript.Run(ctx, s.client, []string{key},
+		now.UnixMilli(), window.Milliseconds(), limit, hex.EncodeToString(member), peekFlag,
+	).Slice()
+	if err != nil {
+		return Window{}, false, err
+	}
+
+	w := Window{Count: int(toInt64(res[1]))}
+	if w.Count > 0 {
+		w.Oldest = time.UnixMilli(toInt64(res[2]))
+		w.Newest = time.UnixMilli(toInt64(res[3]))
+	}
+	return w, toInt64(res[0]) == 1, nil
+}
+
+// toInt64 converts the integer and numeric string replies of the script.
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return int64(f)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"hotpot/internal/core/utils/validator"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ParseBody decodes the request body into a DTO and validates it.
+//
+// Arguments:
+//
+//	ctx - The Fiber context holding the incoming request.
+//	dto - A pointer to the DTO the body should be decoded into.
+//
+// Returns:
+//
+//	An error if the body cannot be decoded or the DTO fails validation.
+//	Callers are expected to answer with CodeValidationError in that case.
+func ParseBody(ctx *fiber.Ctx, dto any) error {
+	if err := ctx.BodyParser(dto); err != nil {
+		return err
+	}
+	return validator.ValidateDTO(dto)
+}
+
+// ParseQuery decodes the query string into a DTO and validates it.
+//
+// Arguments:
+//
+//	ctx - The Fiber context holding the incoming request.
+//	dto - A pointer to the DTO the query parameters should be decoded into.
+//
+// Returns:
+//
+//	An error if the query cannot be decoded or the DTO fails validation.
+//	Callers are expected to answer with CodeValidationError in that case.
+func ParseQuery(ctx *fiber.Ctx, dto any) error {
+	if err := ctx.QueryParser(dto); err != nil {
+		return err
+	}
+	return validator.ValidateDTO(dto)
+}
@@ -8,22 +8,39 @@ import "github.com/gofiber/fiber/v2"
 type StatusCode int
 
 const (
-	OK                  StatusCode = 200 // HTTP 200 OK
-	Redirect            StatusCode = 301 // HTTP 301 Moved Permanently
-	BadRequest          StatusCode = 400 // HTTP 400 Bad Request
-	Unauthorized        StatusCode = 401 // HTTP 401 Unauthorized
-	Forbidden           StatusCode = 403 // HTTP 403 Forbidden
-	NotFound            StatusCode = 404 // HTTP 404 Not Found
-	InternalServerError StatusCode = 500 // HTTP 500 Internal Server Error
+	OK                   StatusCode = 200 // HTTP 200 OK
+	Created              StatusCode = 201 // HTTP 201 Created
+	Accepted             StatusCode = 202 // HTTP 202 Accepted
+	Redirect             StatusCode = 301 // HTTP 301 Moved Permanently
+	BadRequest           StatusCode = 400 // HTTP 400 Bad Request
+	Unauthorized         StatusCode = 401 // HTTP 401 Unauthorized
+	Forbidden            StatusCode = 403 // HTTP 403 Forbidden
+	NotFound             StatusCode = 404 // HTTP 404 Not Found
+	Conflict             StatusCode = 409 // HTTP 409 Conflict
+	PayloadTooLarge      StatusCode = 413 // HTTP 413 Payload Too Large
+	UnsupportedMediaType StatusCode = 415 // HTTP 415 Unsupported Media Type
+	TooManyRequests      StatusCode = 429 // HTTP 429 Too Many Requests
+	InternalServerError  StatusCode = 500 // HTTP 500 Internal Server Error
 )
 
 // CustomCode defines application-specific custom error codes.
 type CustomCode int
 
 const (
-	CodeSuccess         CustomCode = 0   // Indicates successful operation.
-	CodeInternalError   CustomCode = 100 // Indicates an internal server error.
-	CodeValidationError CustomCode = 101 // Indicates a validation error in the request.
+	CodeSuccess            CustomCode = 0   // Indicates successful operation.
+	CodeInternalError      CustomCode = 100 // Indicates an internal server error.
+	CodeValidationError    CustomCode = 101 // Indicates a validation error in the request.
+	CodeAlreadyExists      CustomCode = 102 // Indicates that the resource already exists.
+	CodeInvalidCredentials CustomCode = 103 // Indicates that the supplied credentials are wrong.
+	CodeInvalidToken       CustomCode = 104 // Indicates a missing, malformed or expired token.
+	CodeTokenReused        CustomCode = 105 // Indicates that a rotated refresh token was presented again.
+	CodeNotFound           CustomCode = 106 // Indicates that the requested resource does not exist.
+	CodeOAuthFailed        CustomCode = 107 // Indicates that sign-in with an external provider failed.
+	CodeEmailNotVerified   CustomCode = 108 // Indicates that the action requires a verified email.
+	CodeInvalidMFACode     CustomCode = 109 // Indicates a wrong two-factor or recovery code.
+	CodeTooManyRequests    CustomCode = 110 // Indicates that the client is being rate limited.
+	CodeAccountLocked      CustomCode = 111 // Indicates that the account is temporarily locked after failed attempts.
+	CodeForbidden          CustomCode = 112 // Indicates that the user's roles do not allow the action.
 )
 
 // NewResponse creates a standardized JSON response for the API.
@@ -69,3 +86,31 @@ func NewResponse(ctx *fiber.Ctx, status StatusCode, result any, errCode CustomCo
 
 	return ctx.Status(int(status)).JSON(response)
 }
+
+// Paging describes a page of a cursor-paginated list. NextCursor is opaque to
+// clients, who pass it back to fetch the following page.
+type Paging struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// NewPagedResponse sends a successful response like NewResponse, with the
+// paging information of the list in result added to the envelope:
+//
+//	{
+//	"code": 0,
+//	"message": "success",
+//	"data": <any>,
+//	"paging": <Paging>
+//	}
+func NewPagedResponse(ctx *fiber.Ctx, status StatusCode, result any, paging Paging) error {
+	response := map[string]any{
+		"code":    CodeSuccess,
+		"message": "success",
+		"data":    result,
+		"paging":  paging,
+	}
+
+	return ctx.Status(int(status)).JSON(response)
+}
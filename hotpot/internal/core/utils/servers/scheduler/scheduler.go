@@ -0,0 +1,106 @@
+// Package scheduler runs background jobs at fixed intervals. It implements
+// servers.Server so it starts and stops together with the HTTP server.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a task that runs periodically.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Timeout bounds a single run. Zero means the interval.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Scheduler runs the added jobs until it is stopped. A job never overlaps
+// with itself: a run that takes longer than the interval delays the next one.
+type Scheduler struct {
+	logger *slog.Logger
+	jobs   []Job
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler without jobs.
+func New(logger *slog.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers a job. Jobs must be added before Start. A job without a
+// positive interval is skipped, so that a misconfigured interval disables it
+// rather than crashing the scheduler.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Warn("job skipped, interval must be positive", "job", job.Name, "interval", job.Interval)
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start runs every job once per interval and blocks until Stop is called.
+func (s *Scheduler) Start() error {
+	s.logger.Info("scheduler running", "jobs", len(s.jobs))
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job)
+	}
+	<-s.ctx.Done()
+	return nil
+}
+
+// Stop cancels running jobs and waits for them to return.
+func (s *Scheduler) Stop() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) loop(job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(job)
+		}
+	}
+}
+
+func (s *Scheduler) run(job Job) {
+	timeout := job.Timeout
+	if timeout == 0 {
+		timeout = job.Interval
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("job panicked", "job", job.Name, "panic", r)
+		}
+	}()
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		s.logger.Error("job failed", "job", job.Name, "error", err)
+		return
+	}
+	s.logger.Debug("job finished", "job", job.Name, "duration", time.Since(start))
+}
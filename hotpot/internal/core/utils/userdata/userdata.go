@@ -0,0 +1,225 @@
+// Package userdata collects and erases everything the app stores about a user
+// across modules, so that privacy requests cover modules added later automatically.
+package userdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dataset is one kind of data a module holds about a user.
+type Dataset struct {
+	// Name is the path of the dataset inside the archive without an
+	// extension, e.g. "user/profile".
+	Name string
+	// Records is a slice of values that marshal to JSON objects.
+	Records any
+}
+
+// Exporter is implemented by modules that store data about users.
+type Exporter interface {
+	ExportUserData(ctx context.Context, userID string) ([]Dataset, error)
+}
+
+// Eraser is implemented by modules that store data about users. Erasing
+// must be idempotent, since a failed erasure is retried as a whole.
+type Eraser interface {
+	EraseUserData(ctx context.Context, userID string) error
+}
+
+// Registry holds the exporters and erasers of all registered modules.
+type Registry struct {
+	mu        sync.RWMutex
+	exporters []Exporter
+	erasers   []Eraser
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds the module if it implements any of the interfaces of this
+// package; other modules are ignored.
+func (r *Registry) Register(module any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if e, ok := module.(Exporter); ok {
+		r.exporters = append(r.exporters, e)
+	}
+	if e, ok := module.(Eraser); ok {
+		r.erasers = append(r.erasers, e)
+	}
+}
+
+// Erase deletes or anonymizes the user's data in every module. Modules are
+// erased in reverse registration order, so the auth module, which owns the
+// account and is registered first, goes last. Erasure stops at the first
+// failure so that the account survives until everything else is gone.
+func (r *Registry) Erase(ctx context.Context, userID string) error {
+	r.mu.RLock()
+	erasers := append([]Eraser(nil), r.erasers...)
+	r.mu.RUnlock()
+
+	for i := len(erasers) - 1; i >= 0; i-- {
+		if err := erasers[i].EraseUserData(ctx, userID); err != nil {
+			return fmt.Errorf("erase user data: %w", err)
+		}
+	}
+	return nil
+}
+
+// manifest describes the archive contents in manifest.json.
+type manifest struct {
+	UserID      string    `json:"user_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Datasets    []string  `json:"datasets"`
+}
+
+// Export builds a ZIP archive holding every dataset of the user as both
+// JSON and CSV, plus a manifest.json listing them.
+func (r *Registry) Export(ctx context.Context, userID string) ([]byte, error) {
+	r.mu.RLock()
+	exporters := append([]Exporter(nil), r.exporters...)
+	r.mu.RUnlock()
+
+	var datasets []Dataset
+	for _, e := range exporters {
+		ds, err := e.ExportUserData(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		datasets = append(datasets, ds...)
+	}
+	sort.Slice(datasets, func(i, j int) bool { return datasets[i].Name < datasets[j].Name })
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	m := manifest{UserID: userID, GeneratedAt: time.Now().UTC(), Datasets: []string{}}
+	for _, ds := range datasets {
+		records, err := normalize(ds.Records)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", ds.Name, err)
+		}
+		jsonData, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", ds.Name, err)
+		}
+		if err := writeFile(zw, ds.Name+".json", jsonData, m.GeneratedAt); err != nil {
+			return nil, err
+		}
+		if err := writeFile(zw, ds.Name+".csv", toCSV(records), m.GeneratedAt); err != nil {
+			return nil, err
+		}
+		m.Datasets = append(m.Datasets, ds.Name)
+	}
+
+	manifestData, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(zw, "manifest.json", manifestData, m.GeneratedAt); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte, modified time.Time) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// normalize turns records into generic JSON objects, so that JSON and CSV
+// use the same field names.
+func normalize(records any) ([]map[string]any, error) {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	out := []map[string]any{}
+	if len(data) > 0 && data[0] == '{' {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return nil, err
+		}
+		return append(out, obj), nil
+	}
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []map[string]any{}
+	}
+	return out, nil
+}
+
+// toCSV writes records with one column per field, sorted by name. Nested
+// values are written as JSON.
+func toCSV(records []map[string]any) []byte {
+	seen := map[string]bool{}
+	var columns []string
+	for _, rec := range records {
+		for k := range rec {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(columns)
+	row := make([]string, len(columns))
+	for _, rec := range records {
+		for i, col := range columns {
+			row[i] = csvValue(rec[col])
+		}
+		_ = w.Write(row)
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// csvValue formats a field as a cell. Text that a spreadsheet would run as a
+// formula is prefixed with a quote, since users choose many of the strings.
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -1,8 +1,17 @@
 package auth
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/cfg"
+	"hotpot/internal/core/utils/mailer"
+	"hotpot/internal/core/utils/ratelimit"
+	"hotpot/internal/core/utils/userdata"
 	"hotpot/internal/pkg/auth/ctrl"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/auth/oauth"
+	"hotpot/internal/pkg/auth/repo"
 	"hotpot/internal/pkg/auth/svc"
 	"log/slog"
 )
@@ -12,18 +21,55 @@ type Module struct {
 	Version string
 
 	logger         *slog.Logger
+	ipLimiter      *ratelimit.Limiter
 	AuthController *ctrl.AuthCtrl
+	// Middleware guards routes of any module that needs an authenticated user.
+	Middleware *mw.AuthMw
+	// Service is shared with modules that look up accounts or record audit events.
+	Service *svc.AuthSvc
+	// Mailer is shared with modules that send email.
+	Mailer mailer.Mailer
 }
 
-func New(logger *slog.Logger) *Module {
+func New(logger *slog.Logger, config *cfg.Config) *Module {
+	var providers []svc.OAuthProvider
+	for name, providerCfg := range config.OAuthProviders {
+		if providerCfg.ClientID != "" {
+			providers = append(providers, oauth.NewOIDCProvider(name, providerCfg))
+		}
+	}
+
+	mail, err := mailer.New(mailer.Config{
+		Type:         mailer.Type(config.MailerType),
+		From:         config.MailFrom,
+		SMTPHost:     config.SMTPHost,
+		SMTPPort:     config.SMTPPort,
+		SMTPUsername: config.SMTPUsername,
+		SMTPPassword: config.SMTPPassword,
+		Dir:          config.MailerDir,
+	}, logger)
+	if err != nil {
+		logger.Warn("falling back to log mailer", "error", err)
+		mail = mailer.NewLogMailer(logger)
+	}
+
+	limits, err := ratelimit.NewStore(config.RateLimitRedisURL)
+	if err != nil {
+		logger.Warn("falling back to in-memory rate limits", "error", err)
+		limits = ratelimit.NewMemoryStore()
+	}
+
+	authSvc := svc.NewAuthService(logger, config, repo.NewMemoryStore(), mail, limits, providers)
+
 	mod := &Module{
-		Name:    "auth-module",
-		Version: "v1",
-		logger:  logger,
-		AuthController: ctrl.NewAuthController(
-			logger,
-			svc.NewAuthService(logger),
-		),
+		Name:           "auth-module",
+		Version:        "v1",
+		logger:         logger,
+		ipLimiter:      ratelimit.New(limits, "auth-ip", config.AuthIPLimit, config.AuthIPWindow),
+		AuthController: ctrl.NewAuthController(logger, authSvc),
+		Middleware:     mw.NewAuthMiddleware(logger, authSvc),
+		Service:        authSvc,
+		Mailer:         mail,
 	}
 	return mod
 }
@@ -33,6 +79,58 @@ func (m *Module) InitHTTPRoutes(r fiber.Router) {
 		Group("/api").
 		Group("/" + m.Version)
 
+	// throttle limits credential-guessing endpoints per client IP.
+	throttle := ratelimit.Middleware(m.ipLimiter, func(ctx *fiber.Ctx) string { return ctx.IP() })
+
 	modGroup := root.Group("/auth")
 	modGroup.Get("/ping", m.AuthController.Ping)
+	modGroup.Post("/register", m.AuthController.Register)
+	modGroup.Post("/login", throttle, m.AuthController.Login)
+	modGroup.Post("/login/2fa", throttle, m.AuthController.LoginMFA)
+	modGroup.Post("/refresh", m.AuthController.Refresh)
+	modGroup.Post("/logout", m.AuthController.Logout)
+	modGroup.Post("/logout-all", m.Middleware.Authenticate, m.Middleware.RequireSession, m.AuthController.LogoutAll)
+	modGroup.Get("/me", m.Middleware.Authenticate, m.Middleware.RequireScope(model.ScopeProfileRead), m.AuthController.Me)
+	modGroup.Post("/password/forgot", throttle, m.AuthController.ForgotPassword)
+	modGroup.Post("/password/reset", throttle, m.AuthController.ResetPassword)
+	modGroup.Post("/email/verify", m.AuthController.VerifyEmail)
+	modGroup.Post("/email/verify/resend", m.Middleware.Authenticate, m.Middleware.RequireSession, m.AuthController.ResendVerification)
+
+	mfaGroup := modGroup.Group("/2fa", m.Middleware.Authenticate, m.Middleware.RequireSession, m.Middleware.RequireVerifiedEmail)
+	mfaGroup.Post("/enroll", m.AuthController.EnrollMFA)
+	mfaGroup.Post("/confirm", m.AuthController.ConfirmMFA)
+	mfaGroup.Post("/disable", m.AuthController.DisableMFA)
+	mfaGroup.Post("/recovery-codes", m.AuthController.RegenerateRecoveryCodes)
+
+	adminGroup := modGroup.Group("/users", m.Middleware.Authenticate, m.Middleware.RequireSession,
+		m.Middleware.RequireVerifiedEmail, m.Middleware.RequirePermission(model.PermManageUsers))
+	adminGroup.Put("/:id/roles", m.AuthController.SetRoles)
+
+	modGroup.Get("/audit", m.Middleware.Authenticate, m.Middleware.RequireSession,
+		m.Middleware.RequireVerifiedEmail, m.Middleware.RequirePermission(model.PermReadAudit), m.AuthController.ListAudit)
+
+	sessionGroup := modGroup.Group("/sessions", m.Middleware.Authenticate, m.Middleware.RequireSession)
+	sessionGroup.Get("/", m.AuthController.ListSessions)
+	sessionGroup.Delete("/:id", m.AuthController.RevokeSession)
+
+	// API keys are managed by signed-in users only, so a leaked key cannot mint more keys.
+	apiKeyGroup := modGroup.Group("/api-keys", m.Middleware.Authenticate, m.Middleware.RequireSession)
+	apiKeyGroup.Post("/", m.Middleware.RequireVerifiedEmail, m.AuthController.CreateAPIKey)
+	apiKeyGroup.Get("/", m.AuthController.ListAPIKeys)
+	apiKeyGroup.Delete("/:id", m.AuthController.RevokeAPIKey)
+
+	oauthGroup := modGroup.Group("/oauth/:provider")
+	oauthGroup.Get("/start", m.AuthController.OAuthStart)
+	oauthGroup.Get("/callback", m.AuthController.OAuthCallback)
+	oauthGroup.Post("/callback", m.AuthController.OAuthCallback)
+}
+
+// ExportUserData adds the user's account, devices, API keys and audit trail to data exports.
+func (m *Module) ExportUserData(ctx context.Context, userID string) ([]userdata.Dataset, error) {
+	return m.Service.ExportUserData(ctx, userID)
+}
+
+// EraseUserData deletes the user's account once every other module has erased its data.
+func (m *Module) EraseUserData(ctx context.Context, userID string) error {
+	return m.Service.EraseUserData(ctx, userID)
 }
@@ -0,0 +1,96 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/dto"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/auth/svc"
+	"time"
+)
+
+func (c *AuthCtrl) CreateAPIKey(ctx *fiber.Ctx) error {
+	var req dto.CreateAPIKeyReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	ownerID, ok := c.apiKeyOwner(ctx, req.AccountID)
+	if !ok {
+		return http.NewResponse(ctx, http.Forbidden, nil, http.CodeForbidden, "Insufficient permissions")
+	}
+
+	key, plain, err := c.authSvc.CreateAPIKey(requestCtx(ctx), ownerID, mw.UserID(ctx), req.Name, req.Scopes,
+		time.Duration(req.ExpiresInDays)*24*time.Hour)
+	if err != nil {
+		if errors.Is(err, svc.ErrAccountNotFound) {
+			return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+		}
+		c.logger.Error("create api key failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+
+	res := toAPIKeyRes(key)
+	res.Key = plain
+	return http.NewResponse(ctx, http.Created, res, 0, "")
+}
+
+func (c *AuthCtrl) ListAPIKeys(ctx *fiber.Ctx) error {
+	ownerID, ok := c.apiKeyOwner(ctx, ctx.Query("account_id"))
+	if !ok {
+		return http.NewResponse(ctx, http.Forbidden, nil, http.CodeForbidden, "Insufficient permissions")
+	}
+
+	keys, err := c.authSvc.ListAPIKeys(requestCtx(ctx), ownerID)
+	if err != nil {
+		c.logger.Error("list api keys failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+
+	res := make([]dto.APIKeyRes, len(keys))
+	for i := range keys {
+		res[i] = toAPIKeyRes(&keys[i])
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func (c *AuthCtrl) RevokeAPIKey(ctx *fiber.Ctx) error {
+	ownerID := mw.UserID(ctx)
+	if mw.HasPermission(ctx, model.PermManageUsers) {
+		ownerID = ""
+	}
+
+	if err := c.authSvc.RevokeAPIKey(requestCtx(ctx), ctx.Params("id"), ownerID); err != nil {
+		if errors.Is(err, svc.ErrAPIKeyNotFound) {
+			return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+		}
+		c.logger.Error("revoke api key failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+// apiKeyOwner resolves whose keys a request manages: the caller's own, or
+// any account's for admins.
+func (c *AuthCtrl) apiKeyOwner(ctx *fiber.Ctx, accountID string) (string, bool) {
+	if accountID == "" || accountID == mw.UserID(ctx) {
+		return mw.UserID(ctx), true
+	}
+	return accountID, mw.HasPermission(ctx, model.PermManageUsers)
+}
+
+func toAPIKeyRes(key *model.APIKey) dto.APIKeyRes {
+	return dto.APIKeyRes{
+		ID:         key.ID,
+		AccountID:  key.AccountID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
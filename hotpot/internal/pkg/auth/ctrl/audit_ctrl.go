@@ -0,0 +1,58 @@
+package ctrl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/dto"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+)
+
+// defaultAuditLimit is the page size when the query does not set one.
+const defaultAuditLimit = 50
+
+func (c *AuthCtrl) ListAudit(ctx *fiber.Ctx) error {
+	var q dto.AuditQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultAuditLimit
+	}
+
+	events, total, err := c.authSvc.ListAudit(requestCtx(ctx), repo.AuditFilter{
+		Type:      model.AuditEventType(q.Type),
+		ActorID:   q.ActorID,
+		SubjectID: q.SubjectID,
+		Outcome:   model.AuditOutcome(q.Outcome),
+		From:      q.From,
+		To:        q.To,
+		Limit:     q.Limit,
+		Offset:    q.Offset,
+	})
+	if err != nil {
+		c.logger.Error("list audit events failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+
+	res := dto.AuditPageRes{
+		Items:  make([]dto.AuditEventRes, len(events)),
+		Total:  total,
+		Limit:  q.Limit,
+		Offset: q.Offset,
+	}
+	for i, e := range events {
+		res.Items[i] = dto.AuditEventRes{
+			ID:        e.ID,
+			Type:      string(e.Type),
+			ActorID:   e.ActorID,
+			SubjectID: e.SubjectID,
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			Outcome:   string(e.Outcome),
+			Details:   e.Details,
+			CreatedAt: e.CreatedAt,
+		}
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
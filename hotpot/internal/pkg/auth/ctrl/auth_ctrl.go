@@ -1,10 +1,16 @@
 package ctrl
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/ratelimit"
 	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/dto"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/mw"
 	"hotpot/internal/pkg/auth/svc"
 	"log/slog"
+	"time"
 )
 
 type AuthCtrl struct {
@@ -20,9 +26,262 @@ func NewAuthController(logger *slog.Logger, svc *svc.AuthSvc) *AuthCtrl {
 }
 
 func (c *AuthCtrl) Ping(ctx *fiber.Ctx) error {
-	res, err := c.authSvc.Ping(ctx.Context())
+	res, err := c.authSvc.Ping(requestCtx(ctx))
 	if err != nil {
 		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeInternalError, "Something went wrong!")
 	}
 	return http.NewResponse(ctx, http.OK, res, 0, "")
 }
+
+func (c *AuthCtrl) Register(ctx *fiber.Ctx) error {
+	var req dto.RegisterReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	account, err := c.authSvc.Register(requestCtx(ctx), req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, svc.ErrEmailTaken) {
+			return http.NewResponse(ctx, http.Conflict, nil, http.CodeAlreadyExists, err.Error())
+		}
+		if errors.Is(err, svc.ErrPasswordTooLong) {
+			return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+		}
+		c.logger.Error("register failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+	return http.NewResponse(ctx, http.Created, toAccountRes(account), 0, "")
+}
+
+func (c *AuthCtrl) Login(ctx *fiber.Ctx) error {
+	var req dto.LoginReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	account, err := c.authSvc.Login(requestCtx(ctx), req.Email, req.Password)
+	if err != nil {
+		var locked *ratelimit.ExceededError
+		if errors.As(err, &locked) {
+			return ratelimit.Reject(ctx, locked, http.CodeAccountLocked)
+		}
+		if errors.Is(err, svc.ErrInvalidCredentials) {
+			return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeInvalidCredentials, err.Error())
+		}
+		c.logger.Error("login failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+
+	return c.startSession(ctx, account)
+}
+
+func (c *AuthCtrl) Refresh(ctx *fiber.Ctx) error {
+	var req dto.RefreshReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	tokens, err := c.authSvc.Refresh(requestCtx(ctx), req.RefreshToken, clientInfo(ctx))
+	if err != nil {
+		return c.tokenError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toTokenRes(tokens), 0, "")
+}
+
+func (c *AuthCtrl) Logout(ctx *fiber.Ctx) error {
+	var req dto.RefreshReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	if err := c.authSvc.Logout(requestCtx(ctx), req.RefreshToken); err != nil {
+		return c.tokenError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *AuthCtrl) LogoutAll(ctx *fiber.Ctx) error {
+	if err := c.authSvc.LogoutAll(requestCtx(ctx), mw.UserID(ctx)); err != nil {
+		c.logger.Error("logout all failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *AuthCtrl) SetRoles(ctx *fiber.Ctx) error {
+	var req dto.SetRolesReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	account, err := c.authSvc.SetRoles(requestCtx(ctx), ctx.Params("id"), req.Roles)
+	if err != nil {
+		switch {
+		case errors.Is(err, svc.ErrAccountNotFound):
+			return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+		case errors.Is(err, svc.ErrInvalidRole):
+			return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+		}
+		c.logger.Error("set roles failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+	return http.NewResponse(ctx, http.OK, toAccountRes(account), 0, "")
+}
+
+func (c *AuthCtrl) ForgotPassword(ctx *fiber.Ctx) error {
+	var req dto.ForgotPasswordReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	// Always answer the same way so the endpoint does not reveal which emails exist.
+	if err := c.authSvc.RequestPasswordReset(requestCtx(ctx), req.Email); err != nil {
+		c.logger.Error("request password reset failed", "error", err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *AuthCtrl) ResetPassword(ctx *fiber.Ctx) error {
+	var req dto.ResetPasswordReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	if err := c.authSvc.ResetPassword(requestCtx(ctx), req.Token, req.Password); err != nil {
+		if errors.Is(err, svc.ErrPasswordTooLong) {
+			return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+		}
+		return c.tokenError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *AuthCtrl) VerifyEmail(ctx *fiber.Ctx) error {
+	var req dto.VerifyEmailReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	if err := c.authSvc.VerifyEmail(requestCtx(ctx), req.Token); err != nil {
+		return c.tokenError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *AuthCtrl) ResendVerification(ctx *fiber.Ctx) error {
+	err := c.authSvc.SendEmailVerification(requestCtx(ctx), mw.UserID(ctx))
+	if err != nil {
+		if errors.Is(err, svc.ErrAlreadyVerified) {
+			return http.NewResponse(ctx, http.Conflict, nil, http.CodeAlreadyExists, err.Error())
+		}
+		c.logger.Error("resend verification failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *AuthCtrl) OAuthStart(ctx *fiber.Ctx) error {
+	authURL, state, err := c.authSvc.StartOAuth(requestCtx(ctx), ctx.Params("provider"))
+	if err != nil {
+		return c.oauthError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, dto.OAuthStartRes{AuthorizationURL: authURL, State: state}, 0, "")
+}
+
+// OAuthCallback handles both query-string callbacks and the form_post
+// callbacks used by Apple.
+func (c *AuthCtrl) OAuthCallback(ctx *fiber.Ctx) error {
+	param := func(key string) string {
+		if v := ctx.Query(key); v != "" {
+			return v
+		}
+		return ctx.FormValue(key)
+	}
+
+	if providerErr := param("error"); providerErr != "" {
+		return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeOAuthFailed, "Provider returned error: "+providerErr)
+	}
+	state, code := param("state"), param("code")
+	if state == "" || code == "" {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, "Missing state or code")
+	}
+
+	account, err := c.authSvc.CompleteOAuth(requestCtx(ctx), ctx.Params("provider"), state, code)
+	if err != nil {
+		return c.oauthError(ctx, err)
+	}
+
+	return c.startSession(ctx, account)
+}
+
+func (c *AuthCtrl) Me(ctx *fiber.Ctx) error {
+	account, err := c.authSvc.Account(requestCtx(ctx), mw.UserID(ctx))
+	if err != nil {
+		return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeInvalidToken, "Account no longer exists")
+	}
+	return http.NewResponse(ctx, http.OK, toAccountRes(account), 0, "")
+}
+
+// startSession answers a successful first login step with either tokens
+// or a request for the second factor.
+func (c *AuthCtrl) startSession(ctx *fiber.Ctx, account *model.Account) error {
+	res, err := c.authSvc.StartSession(requestCtx(ctx), account, clientInfo(ctx))
+	if err != nil {
+		c.logger.Error("start session failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+
+	if res.MFAToken != "" {
+		return http.NewResponse(ctx, http.OK, dto.LoginRes{MFARequired: true, MFAToken: res.MFAToken}, 0, "")
+	}
+	tokens := toTokenRes(res.Tokens)
+	return http.NewResponse(ctx, http.OK, dto.LoginRes{TokenRes: &tokens}, 0, "")
+}
+
+// tokenError maps refresh and emailed token errors to responses.
+func (c *AuthCtrl) tokenError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrTokenReused):
+		return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeTokenReused, err.Error())
+	case errors.Is(err, svc.ErrInvalidToken):
+		return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeInvalidToken, err.Error())
+	default:
+		c.logger.Error("refresh token handling failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
+
+// oauthError maps OAuth flow errors to responses.
+func (c *AuthCtrl) oauthError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrUnknownProvider):
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	case errors.Is(err, svc.ErrInvalidState):
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeOAuthFailed, err.Error())
+	case errors.Is(err, svc.ErrOAuthFailed):
+		return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeOAuthFailed, err.Error())
+	default:
+		c.logger.Error("oauth flow failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
+
+func toAccountRes(account *model.Account) dto.AccountRes {
+	return dto.AccountRes{
+		ID:            account.ID,
+		Email:         account.Email,
+		EmailVerified: account.EmailVerifiedAt != nil,
+		Roles:         account.Roles,
+		CreatedAt:     account.CreatedAt,
+	}
+}
+
+func toTokenRes(tokens *svc.TokenPair) dto.TokenRes {
+	return dto.TokenRes{
+		AccessToken:      tokens.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(time.Until(tokens.AccessExpiresAt).Seconds()),
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}
+}
@@ -0,0 +1,94 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/ratelimit"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/dto"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/auth/svc"
+)
+
+func (c *AuthCtrl) LoginMFA(ctx *fiber.Ctx) error {
+	var req dto.MFALoginReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	tokens, err := c.authSvc.CompleteMFALogin(requestCtx(ctx), req.MFAToken, req.Code, clientInfo(ctx))
+	if err != nil {
+		return c.mfaError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toTokenRes(tokens), 0, "")
+}
+
+func (c *AuthCtrl) EnrollMFA(ctx *fiber.Ctx) error {
+	enrollment, err := c.authSvc.EnrollMFA(requestCtx(ctx), mw.UserID(ctx))
+	if err != nil {
+		return c.mfaError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, dto.MFAEnrollRes{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+		QRCode: enrollment.QRCode,
+	}, 0, "")
+}
+
+func (c *AuthCtrl) ConfirmMFA(ctx *fiber.Ctx) error {
+	var req dto.MFACodeReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	codes, err := c.authSvc.ConfirmMFA(requestCtx(ctx), mw.UserID(ctx), req.Code)
+	if err != nil {
+		return c.mfaError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, dto.RecoveryCodesRes{RecoveryCodes: codes}, 0, "")
+}
+
+func (c *AuthCtrl) DisableMFA(ctx *fiber.Ctx) error {
+	var req dto.MFACodeReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	if err := c.authSvc.DisableMFA(requestCtx(ctx), mw.UserID(ctx), req.Code); err != nil {
+		return c.mfaError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *AuthCtrl) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	var req dto.MFACodeReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	codes, err := c.authSvc.RegenerateRecoveryCodes(requestCtx(ctx), mw.UserID(ctx), req.Code)
+	if err != nil {
+		return c.mfaError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, dto.RecoveryCodesRes{RecoveryCodes: codes}, 0, "")
+}
+
+// mfaError maps two-factor errors to responses.
+func (c *AuthCtrl) mfaError(ctx *fiber.Ctx, err error) error {
+	var locked *ratelimit.ExceededError
+	switch {
+	case errors.As(err, &locked):
+		return ratelimit.Reject(ctx, locked, http.CodeAccountLocked)
+	case errors.Is(err, svc.ErrInvalidToken):
+		return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeInvalidToken, err.Error())
+	case errors.Is(err, svc.ErrInvalidCode):
+		return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeInvalidMFACode, err.Error())
+	case errors.Is(err, svc.ErrMFAAlreadyEnabled):
+		return http.NewResponse(ctx, http.Conflict, nil, http.CodeAlreadyExists, err.Error())
+	case errors.Is(err, svc.ErrMFANotEnabled), errors.Is(err, svc.ErrNoPendingMFASetup):
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	default:
+		c.logger.Error("two-factor request failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
@@ -0,0 +1,62 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/dto"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/auth/svc"
+)
+
+// HeaderDeviceName lets client apps name the device a session is started on.
+const HeaderDeviceName = "X-Device-Name"
+
+func (c *AuthCtrl) ListSessions(ctx *fiber.Ctx) error {
+	sessions, err := c.authSvc.ListSessions(requestCtx(ctx), mw.UserID(ctx))
+	if err != nil {
+		c.logger.Error("list sessions failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+
+	current := mw.Principal(ctx).SessionID
+	res := make([]dto.SessionRes, len(sessions))
+	for i, s := range sessions {
+		res[i] = dto.SessionRes{
+			ID:         s.ID,
+			DeviceName: s.DeviceName,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			Current:    s.ID == current,
+		}
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func (c *AuthCtrl) RevokeSession(ctx *fiber.Ctx) error {
+	if err := c.authSvc.RevokeSession(requestCtx(ctx), mw.UserID(ctx), ctx.Params("id")); err != nil {
+		if errors.Is(err, svc.ErrSessionNotFound) {
+			return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+		}
+		c.logger.Error("revoke session failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+// requestCtx returns the request context carrying the caller for audit events.
+func requestCtx(ctx *fiber.Ctx) context.Context {
+	return svc.WithAuditActor(ctx.Context(), mw.UserID(ctx), clientInfo(ctx))
+}
+
+// clientInfo describes the device the request comes from.
+func clientInfo(ctx *fiber.Ctx) svc.ClientInfo {
+	return svc.ClientInfo{
+		DeviceName: ctx.Get(HeaderDeviceName),
+		IP:         ctx.IP(),
+		UserAgent:  ctx.Get(fiber.HeaderUserAgent),
+	}
+}
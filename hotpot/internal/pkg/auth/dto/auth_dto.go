@@ -0,0 +1,151 @@
+package dto
+
+import (
+	"hotpot/internal/pkg/auth/model"
+	"time"
+)
+
+type RegisterReq struct {
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type LoginReq struct {
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+type RefreshReq struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type AccountRes struct {
+	ID            string       `json:"id"`
+	Email         string       `json:"email"`
+	EmailVerified bool         `json:"email_verified"`
+	Roles         []model.Role `json:"roles"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+type SetRolesReq struct {
+	Roles []model.Role `json:"roles" validate:"required,min=1,dive,oneof=user dietitian admin"`
+}
+
+// LoginRes carries either the token pair or, when two-factor auth is
+// enabled, the token for the second login step.
+type LoginRes struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	*TokenRes
+}
+
+type TokenRes struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresIn        int64     `json:"expires_in"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type OAuthStartRes struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email" validate:"required,email,max=254"`
+}
+
+type ResetPasswordReq struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+type VerifyEmailReq struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type MFALoginReq struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,max=16"`
+}
+
+type MFACodeReq struct {
+	Code string `json:"code" validate:"required,max=16"`
+}
+
+type MFAEnrollRes struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+	// QRCode is a base64-encoded PNG of URI.
+	QRCode []byte `json:"qr_png"`
+}
+
+type RecoveryCodesRes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type CreateAPIKeyReq struct {
+	Name   string        `json:"name" validate:"required,max=100"`
+	Scopes []model.Scope `json:"scopes" validate:"required,min=1,dive,oneof=profile:read profile:write meals:read meals:write diets:read diets:write"`
+	// ExpiresInDays of 0 creates a key that does not expire.
+	ExpiresInDays int `json:"expires_in_days" validate:"min=0,max=3650"`
+	// AccountID lets admins create keys for another account.
+	AccountID string `json:"account_id" validate:"omitempty,uuid"`
+}
+
+type APIKeyRes struct {
+	ID         string        `json:"id"`
+	AccountID  string        `json:"account_id"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	Scopes     []model.Scope `json:"scopes"`
+	CreatedAt  time.Time     `json:"created_at"`
+	ExpiresAt  *time.Time    `json:"expires_at"`
+	LastUsedAt *time.Time    `json:"last_used_at"`
+	RevokedAt  *time.Time    `json:"revoked_at"`
+	// Key is the plain key, only returned when it is created.
+	Key string `json:"key,omitempty"`
+}
+
+type SessionRes struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Current marks the session the request was made with.
+	Current bool `json:"current"`
+}
+
+type AuditQuery struct {
+	Type      string `query:"type"`
+	ActorID   string `query:"actor_id" validate:"omitempty,uuid"`
+	SubjectID string `query:"subject_id" validate:"omitempty,uuid"`
+	Outcome   string `query:"outcome" validate:"omitempty,oneof=success failure"`
+	// From and To are RFC 3339 timestamps bounding CreatedAt.
+	From   time.Time `query:"from"`
+	To     time.Time `query:"to"`
+	Limit  int       `query:"limit" validate:"min=0,max=200"`
+	Offset int       `query:"offset" validate:"min=0"`
+}
+
+type AuditEventRes struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	ActorID   string            `json:"actor_id"`
+	SubjectID string            `json:"subject_id"`
+	IP        string            `json:"ip"`
+	UserAgent string            `json:"user_agent"`
+	Outcome   string            `json:"outcome"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type AuditPageRes struct {
+	Items  []AuditEventRes `json:"items"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
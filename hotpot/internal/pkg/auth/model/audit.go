@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// AuditEventType names a security-relevant action.
+type AuditEventType string
+
+const (
+	AuditLogin          AuditEventType = "login"
+	AuditPasswordChange AuditEventType = "password_change"
+	AuditMFAEnable      AuditEventType = "mfa_enable"
+	AuditMFADisable     AuditEventType = "mfa_disable"
+	AuditRecoveryCodes  AuditEventType = "mfa_recovery_codes"
+	AuditTokenRevoke    AuditEventType = "token_revoke"
+	AuditTokenReuse     AuditEventType = "token_reuse"
+	AuditAPIKeyCreate   AuditEventType = "api_key_create"
+	AuditAPIKeyRevoke   AuditEventType = "api_key_revoke"
+	AuditRoleChange     AuditEventType = "role_change"
+	AuditAccountDelete  AuditEventType = "account_delete"
+	// AuditCoachingChange records invitations to, and the start and end of,
+	// coach-client relationships.
+	AuditCoachingChange AuditEventType = "coaching_change"
+	// AuditDataAccess records a user reading someone else's data, e.g. a
+	// dietitian opening their client list.
+	AuditDataAccess AuditEventType = "data_access"
+)
+
+// AuditOutcome tells whether the audited action succeeded.
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+)
+
+// AuditEvent is an append-only record of a security-relevant action.
+type AuditEvent struct {
+	ID   string
+	Type AuditEventType
+	// ActorID is the account that performed the action. It is empty when
+	// nobody could be identified, e.g. a login with an unknown email.
+	ActorID string
+	// SubjectID is the account the action was performed on.
+	SubjectID string
+	IP        string
+	UserAgent string
+	Outcome   AuditOutcome
+	// Details holds event specific context such as the failure reason.
+	Details   map[string]string
+	CreatedAt time.Time
+}
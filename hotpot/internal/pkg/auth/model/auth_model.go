@@ -0,0 +1,120 @@
+package model
+
+import "time"
+
+// Account holds the login credentials of a single user.
+type Account struct {
+	ID           string
+	Email        string
+	PasswordHash []byte
+	Roles        []Role
+	// EmailVerifiedAt is nil until the user proves they own the email address.
+	EmailVerifiedAt *time.Time
+	// TOTPSecret is the confirmed authenticator secret; TOTPPendingSecret
+	// holds a secret that was enrolled but not yet confirmed with a code.
+	TOTPSecret        string
+	TOTPPendingSecret string
+	TOTPEnabledAt     *time.Time
+	// TOTPLastStep is the last accepted time step, so codes cannot be replayed.
+	TOTPLastStep int64
+	// RecoveryCodeHashes are SHA-256 hashes of unused recovery codes.
+	RecoveryCodeHashes []string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// AccountSummary is the part of an account other modules may see.
+type AccountSummary struct {
+	ID            string
+	Email         string
+	Roles         []Role
+	EmailVerified bool
+	CreatedAt     time.Time
+}
+
+// AccountQuery selects accounts for directory lookups. Zero fields match
+// every account.
+type AccountQuery struct {
+	// Email matches addresses containing it, ignoring case.
+	Email string
+	Role  Role
+	// Verified, when set, matches on whether the email address is verified.
+	Verified    *bool
+	CreatedFrom time.Time
+	// CreatedTo is exclusive.
+	CreatedTo time.Time
+}
+
+// Session is a refresh token family. Every refresh token rotated out of the
+// same login belongs to one session, so revoking the session kills them all.
+type Session struct {
+	ID        string
+	AccountID string
+	// DeviceName, IP and UserAgent describe the client that last used the
+	// session, so users can recognise their devices.
+	DeviceName string
+	IP         string
+	UserAgent  string
+	CreatedAt  time.Time
+	// LastSeenAt is updated on every refresh.
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+}
+
+// RefreshToken is a server-side record of an issued refresh token.
+// Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID        string
+	SessionID string
+	AccountID string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	// RotatedAt is set once the token has been exchanged for a new one.
+	RotatedAt *time.Time
+}
+
+// Identity links an account to a user of an external OAuth provider.
+type Identity struct {
+	ID        string
+	AccountID string
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+// OAuthState remembers a started authorization-code flow until its callback.
+type OAuthState struct {
+	State        string
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+}
+
+// ActionToken records an emailed single-use token, such as a password reset link.
+type ActionToken struct {
+	ID        string
+	AccountID string
+	Purpose   string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// APIKey is a long-lived credential for machine clients. Only the SHA-256
+// hash of the key is stored; Prefix is kept in clear so keys can be told apart.
+type APIKey struct {
+	ID         string
+	AccountID  string
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []Scope
+	CreatedBy  string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
@@ -0,0 +1,49 @@
+package model
+
+import "slices"
+
+// Role groups the permissions of a kind of user.
+type Role string
+
+const (
+	RoleUser      Role = "user"      // Regular end user tracking their own food.
+	RoleDietitian Role = "dietitian" // Coach who manages the diets of their clients.
+	RoleAdmin     Role = "admin"     // Staff who manage users and curate the food catalog.
+)
+
+// Roles lists every known role.
+var Roles = []Role{RoleUser, RoleDietitian, RoleAdmin}
+
+// Permission is a single action that can be granted to roles.
+type Permission string
+
+const (
+	PermManageClients Permission = "clients:manage" // Read and edit the data of coached clients.
+	PermManageCatalog Permission = "catalog:manage" // Create and edit shared foods, recipes and templates.
+	PermManageUsers   Permission = "users:manage"   // Look up users and change their roles.
+	PermReadAudit     Permission = "audit:read"     // Read the security audit log.
+)
+
+// rolePermissions maps each role to the permissions it grants. Every
+// authenticated user may manage their own data, so RoleUser grants nothing extra.
+var rolePermissions = map[Role][]Permission{
+	RoleUser:      {},
+	RoleDietitian: {PermManageClients},
+	RoleAdmin:     {PermManageCatalog, PermManageUsers, PermReadAudit},
+}
+
+// ValidRole reports whether r is a known role.
+func ValidRole(r Role) bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// HasPermission reports whether any of the roles grants the permission.
+func HasPermission(roles []Role, perm Permission) bool {
+	for _, r := range roles {
+		if slices.Contains(rolePermissions[r], perm) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package model
+
+// Scope limits what an API key may do. Session tokens are not scoped.
+type Scope string
+
+const (
+	ScopeProfileRead  Scope = "profile:read"
+	ScopeProfileWrite Scope = "profile:write"
+	ScopeMealsRead    Scope = "meals:read"
+	ScopeMealsWrite   Scope = "meals:write"
+	ScopeDietsRead    Scope = "diets:read"
+	ScopeDietsWrite   Scope = "diets:write"
+)
+
+// Scopes lists every known scope.
+var Scopes = []Scope{
+	ScopeProfileRead, ScopeProfileWrite,
+	ScopeMealsRead, ScopeMealsWrite,
+	ScopeDietsRead, ScopeDietsWrite,
+}
@@ -0,0 +1,157 @@
+package mw
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/svc"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Keys under which the middleware stores the authenticated identity in fiber.Ctx.Locals.
+const (
+	LocalUserID    = "auth.user_id"
+	LocalPrincipal = "auth.principal"
+)
+
+// AuthMw provides Fiber middleware that modules attach to their route groups.
+type AuthMw struct {
+	logger  *slog.Logger
+	authSvc *svc.AuthSvc
+}
+
+func NewAuthMiddleware(logger *slog.Logger, svc *svc.AuthSvc) *AuthMw {
+	return &AuthMw{
+		logger:  logger,
+		authSvc: svc,
+	}
+}
+
+// Authenticate requires either "Authorization: Bearer <access token>" or
+// "Authorization: ApiKey <key>" and stores the caller in the request locals.
+func (m *AuthMw) Authenticate(ctx *fiber.Ctx) error {
+	scheme, credential, ok := strings.Cut(ctx.Get(fiber.HeaderAuthorization), " ")
+	if !ok || credential == "" {
+		return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeInvalidToken, "Missing credentials")
+	}
+
+	var principal *svc.Principal
+	var err error
+	switch {
+	case strings.EqualFold(scheme, "Bearer"):
+		principal, err = m.authSvc.AuthenticateToken(ctx.Context(), credential)
+	case strings.EqualFold(scheme, "ApiKey"):
+		principal, err = m.authSvc.AuthenticateAPIKey(ctx.Context(), credential)
+	default:
+		return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeInvalidToken, "Unsupported authorization scheme")
+	}
+	if err != nil {
+		if errors.Is(err, svc.ErrInvalidToken) {
+			return http.NewResponse(ctx, http.Unauthorized, nil, http.CodeInvalidToken, err.Error())
+		}
+		m.logger.Error("authenticate request failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+
+	ctx.Locals(LocalUserID, principal.UserID)
+	ctx.Locals(LocalPrincipal, principal)
+	return ctx.Next()
+}
+
+// RequireSession rejects API key callers, for account management routes that
+// must only be reached by a signed-in user. It must run after Authenticate.
+func (m *AuthMw) RequireSession(ctx *fiber.Ctx) error {
+	if p := Principal(ctx); p == nil || p.IsAPIKey() {
+		return http.NewResponse(ctx, http.Forbidden, nil, http.CodeForbidden, "Not available to API keys")
+	}
+	return ctx.Next()
+}
+
+// RequireScope rejects API keys that were not granted the scope. Session
+// callers always pass. It must run after Authenticate.
+func (m *AuthMw) RequireScope(scope model.Scope) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if p := Principal(ctx); p == nil || !p.HasScope(scope) {
+			return http.NewResponse(ctx, http.Forbidden, nil, http.CodeForbidden, "API key lacks scope "+string(scope))
+		}
+		return ctx.Next()
+	}
+}
+
+// RequireVerifiedEmail rejects users who have not confirmed their email yet.
+// It must run after Authenticate.
+func (m *AuthMw) RequireVerifiedEmail(ctx *fiber.Ctx) error {
+	if p := Principal(ctx); p == nil || !p.EmailVerified {
+		return http.NewResponse(ctx, http.Forbidden, nil, http.CodeEmailNotVerified, "Email address is not verified")
+	}
+	return ctx.Next()
+}
+
+// RequireRole rejects users who hold none of the given roles.
+// It must run after Authenticate.
+func (m *AuthMw) RequireRole(roles ...model.Role) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		for _, r := range roles {
+			if HasRole(ctx, r) {
+				return ctx.Next()
+			}
+		}
+		return http.NewResponse(ctx, http.Forbidden, nil, http.CodeForbidden, "Insufficient role")
+	}
+}
+
+// RequirePermission rejects users whose roles do not grant the permission.
+// It must run after Authenticate.
+func (m *AuthMw) RequirePermission(perm model.Permission) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if !HasPermission(ctx, perm) {
+			return http.NewResponse(ctx, http.Forbidden, nil, http.CodeForbidden, "Insufficient permissions")
+		}
+		return ctx.Next()
+	}
+}
+
+// Principal returns the authenticated caller, or nil if the request did not
+// pass through Authenticate.
+func Principal(ctx *fiber.Ctx) *svc.Principal {
+	p, _ := ctx.Locals(LocalPrincipal).(*svc.Principal)
+	return p
+}
+
+// RequestContext returns the request context carrying the caller and their
+// client, so that services of any module can record audit events.
+func RequestContext(ctx *fiber.Ctx) context.Context {
+	return svc.WithAuditActor(ctx.Context(), UserID(ctx), svc.ClientInfo{
+		IP:        ctx.IP(),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+	})
+}
+
+// UserID returns the ID of the authenticated user, or an empty string
+// if the request did not pass through Authenticate.
+func UserID(ctx *fiber.Ctx) string {
+	id, _ := ctx.Locals(LocalUserID).(string)
+	return id
+}
+
+// Roles returns the roles of the authenticated user.
+func Roles(ctx *fiber.Ctx) []model.Role {
+	if p := Principal(ctx); p != nil {
+		return p.Roles
+	}
+	return nil
+}
+
+// HasRole reports whether the authenticated user holds the role.
+func HasRole(ctx *fiber.Ctx, role model.Role) bool {
+	return slices.Contains(Roles(ctx), role)
+}
+
+// HasPermission reports whether the authenticated user's roles grant the permission.
+func HasPermission(ctx *fiber.Ctx, perm model.Permission) bool {
+	return model.HasPermission(Roles(ctx), perm)
+}
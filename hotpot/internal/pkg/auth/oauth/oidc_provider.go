@@ -0,0 +1,171 @@
+// Package oauth implements svc.OAuthProvider for OAuth2/OpenID Connect identity providers.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hotpot/internal/core/cfg"
+	"hotpot/internal/pkg/auth/svc"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// OIDCProvider signs users in through a standard OAuth2/OIDC provider such as
+// Google or Apple. The identity is read from the verified ID token when a JWKS
+// URL is configured, and from the userinfo endpoint otherwise.
+//
+// Apple expects the client secret to be an ES256 JWT; it is configured as a
+// pre-generated token in ClientSecret.
+type OIDCProvider struct {
+	name   string
+	cfg    cfg.OAuthProviderConfig
+	oauth  *oauth2.Config
+	client *http.Client
+	jwks   *jwksCache
+}
+
+// NewOIDCProvider creates a provider from its configuration.
+func NewOIDCProvider(name string, config cfg.OAuthProviderConfig) *OIDCProvider {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	p := &OIDCProvider{
+		name: name,
+		cfg:  config,
+		oauth: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Scopes:       config.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  config.AuthURL,
+				TokenURL: config.TokenURL,
+			},
+		},
+		client: client,
+	}
+	if config.JWKSURL != "" {
+		p.jwks = newJWKSCache(config.JWKSURL, client)
+	}
+	return p
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+func (p *OIDCProvider) AuthCodeURL(state, codeChallenge, nonce string) string {
+	opts := []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge", codeChallenge),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	}
+	if p.cfg.ResponseMode != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("response_mode", p.cfg.ResponseMode))
+	}
+	return p.oauth.AuthCodeURL(state, opts...)
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*svc.ExternalIdentity, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	if p.jwks != nil {
+		rawIDToken, _ := token.Extra("id_token").(string)
+		if rawIDToken == "" {
+			return nil, errors.New("provider returned no id_token")
+		}
+		return p.verifyIDToken(ctx, rawIDToken, nonce)
+	}
+	return p.userInfo(ctx, token)
+}
+
+// idTokenClaims are the OIDC claims read from an ID token or userinfo response.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Nonce         string `json:"nonce"`
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, rawIDToken, nonce string) (*svc.ExternalIdentity, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims,
+		func(t *jwt.Token) (any, error) {
+			kid, _ := t.Header["kid"].(string)
+			return p.jwks.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("verify id_token: %w", err)
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+
+	return &svc.ExternalIdentity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: isTrue(claims.EmailVerified),
+	}, nil
+}
+
+func (p *OIDCProvider) userInfo(ctx context.Context, token *oauth2.Token) (*svc.ExternalIdentity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	token.SetAuthHeader(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch userinfo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch userinfo: unexpected status %d", resp.StatusCode)
+	}
+
+	var info struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified any    `json:"email_verified"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("decode userinfo: %w", err)
+	}
+	if info.Subject == "" {
+		return nil, errors.New("userinfo has no subject")
+	}
+
+	return &svc.ExternalIdentity{
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: isTrue(info.EmailVerified),
+	}, nil
+}
+
+// isTrue accepts both JSON booleans and the "true" strings Apple sends.
+func isTrue(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return b == "true"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,458 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hotpot/internal/core/cfg"
+	"hotpot/internal/core/utils/mailer"
+	"hotpot/internal/core/utils/ratelimit"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"hotpot/internal/pkg/auth/svc"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	testProvider = "stub"
+	testClientID = "hotpot-client"
+	testKeyID    = "stub-key"
+)
+
+// stubIdP is an OIDC provider serving the token and JWKS endpoints. Tests
+// play the browser: they read the authorization URL and call authorize to
+// get the code the provider would redirect back with.
+type stubIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]pendingCode
+}
+
+type pendingCode struct {
+	challenge string
+	nonce     string
+	subject   string
+	email     string
+	verified  bool
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &stubIdP{t: t, key: key, codes: map[string]pendingCode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *stubIdP) config() cfg.OAuthProviderConfig {
+	return cfg.OAuthProviderConfig{
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost/auth/oauth/stub/callback",
+		AuthURL:     idp.server.URL + "/authorize",
+		TokenURL:    idp.server.URL + "/token",
+		JWKSURL:     idp.server.URL + "/jwks",
+		Issuer:      idp.server.URL,
+		Scopes:      []string{"openid", "email"},
+	}
+}
+
+// authorize signs the user in at the provider and returns the code for the
+// callback, echoing the nonce of the authorization URL.
+func (idp *stubIdP) authorize(authURL, subject, email string, verified bool) string {
+	idp.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" {
+		idp.t.Fatalf("code_challenge_method = %q, want S256", q.Get("code_challenge_method"))
+	}
+
+	code := uuid.NewString()
+	idp.mu.Lock()
+	idp.codes[code] = pendingCode{
+		challenge: q.Get("code_challenge"),
+		nonce:     q.Get("nonce"),
+		subject:   subject,
+		email:     email,
+		verified:  verified,
+	}
+	idp.mu.Unlock()
+	return code
+}
+
+// setNonce replaces the nonce the ID token of code will carry.
+func (idp *stubIdP) setNonce(code, nonce string) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	pending := idp.codes[code]
+	pending.nonce = nonce
+	idp.codes[code] = pending
+}
+
+func (idp *stubIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	idp.mu.Lock()
+	pending, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != pending.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"error":"invalid_grant"}`)
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            testClientID,
+		"sub":            pending.subject,
+		"email":          pending.email,
+		"email_verified": pending.verified,
+		"nonce":          pending.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+	})
+	idToken.Header["kid"] = testKeyID
+	signed, err := idToken.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "stub-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     signed,
+	})
+}
+
+func (idp *stubIdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	pub := idp.key.PublicKey
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kid": testKeyID,
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func newTestAuthSvc(t *testing.T, idp *stubIdP) (*svc.AuthSvc, *repo.Store) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	config := &cfg.Config{
+		AdminEmails:         []string{"admin@example.com"},
+		LockoutThreshold:    5,
+		LockoutBase:         time.Minute,
+		LockoutMax:          time.Hour,
+		LockoutWindow:       time.Hour,
+		PasswordResetLimit:  3,
+		PasswordResetWindow: time.Hour,
+	}
+	store := repo.NewMemoryStore()
+	provider := NewOIDCProvider(testProvider, idp.config())
+	authSvc := svc.NewAuthService(logger, config, store, mailer.NewLogMailer(logger),
+		ratelimit.NewMemoryStore(), []svc.OAuthProvider{provider})
+	return authSvc, store
+}
+
+func createAccount(t *testing.T, store *repo.Store, email string, verified bool) *model.Account {
+	t.Helper()
+	now := time.Now().UTC()
+	account := &model.Account{
+		ID:           uuid.NewString(),
+		Email:        email,
+		PasswordHash: []byte("hash"),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if verified {
+		account.EmailVerifiedAt = &now
+	}
+	if err := store.Accounts.Create(context.Background(), account); err != nil {
+		t.Fatal(err)
+	}
+	return account
+}
+
+func TestOAuthStartAndCallback(t *testing.T) {
+	ctx := context.Background()
+	idp := newStubIdP(t)
+	authSvc, store := newTestAuthSvc(t, idp)
+
+	authURL, state, err := authSvc.StartOAuth(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Query().Get("state"); got != state {
+		t.Fatalf("state in authorization URL = %q, want %q", got, state)
+	}
+	if got := u.Query().Get("client_id"); got != testClientID {
+		t.Fatalf("client_id = %q, want %q", got, testClientID)
+	}
+
+	code := idp.authorize(authURL, "subject-1", "New.User@example.com", true)
+	account, err := authSvc.CompleteOAuth(ctx, testProvider, state, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Email != "new.user@example.com" {
+		t.Errorf("email = %q, want it normalized", account.Email)
+	}
+	if account.EmailVerifiedAt == nil {
+		t.Error("account created from a verified identity is not verified")
+	}
+	if account.PasswordHash != nil {
+		t.Error("account created from an identity has a password")
+	}
+
+	identity, err := store.Identities.FindBySubject(ctx, testProvider, "subject-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.AccountID != account.ID {
+		t.Errorf("identity linked to %q, want %q", identity.AccountID, account.ID)
+	}
+
+	// Signing in again resolves the identity to the same account.
+	authURL, state, err = authSvc.StartOAuth(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code = idp.authorize(authURL, "subject-1", "new.user@example.com", true)
+	again, err := authSvc.CompleteOAuth(ctx, testProvider, state, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != account.ID {
+		t.Errorf("second sign-in returned account %q, want %q", again.ID, account.ID)
+	}
+}
+
+func TestOAuthCallbackRejectsStateMismatch(t *testing.T) {
+	ctx := context.Background()
+	idp := newStubIdP(t)
+	authSvc, _ := newTestAuthSvc(t, idp)
+
+	authURL, state, err := authSvc.StartOAuth(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := idp.authorize(authURL, "subject-1", "user@example.com", true)
+
+	if _, err := authSvc.CompleteOAuth(ctx, testProvider, "forged-state", code); !errors.Is(err, svc.ErrInvalidState) {
+		t.Fatalf("unknown state: err = %v, want ErrInvalidState", err)
+	}
+	if _, err := authSvc.CompleteOAuth(ctx, "google", state, code); !errors.Is(err, svc.ErrUnknownProvider) {
+		t.Fatalf("unknown provider: err = %v, want ErrUnknownProvider", err)
+	}
+	if _, err := authSvc.CompleteOAuth(ctx, testProvider, state, code); err != nil {
+		t.Fatalf("valid state: %v", err)
+	}
+	// A state is good for a single callback.
+	if _, err := authSvc.CompleteOAuth(ctx, testProvider, state, code); !errors.Is(err, svc.ErrInvalidState) {
+		t.Fatalf("replayed state: err = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestOAuthCallbackRejectsNonceMismatch(t *testing.T) {
+	ctx := context.Background()
+	idp := newStubIdP(t)
+	authSvc, store := newTestAuthSvc(t, idp)
+
+	authURL, state, err := authSvc.StartOAuth(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := idp.authorize(authURL, "subject-1", "user@example.com", true)
+	idp.setNonce(code, "replayed-nonce")
+
+	if _, err := authSvc.CompleteOAuth(ctx, testProvider, state, code); !errors.Is(err, svc.ErrOAuthFailed) {
+		t.Fatalf("err = %v, want ErrOAuthFailed", err)
+	}
+	if _, err := store.Identities.FindBySubject(ctx, testProvider, "subject-1"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("identity linked despite the nonce mismatch: err = %v", err)
+	}
+}
+
+func TestOAuthCallbackRejectsUnverifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	idp := newStubIdP(t)
+	authSvc, store := newTestAuthSvc(t, idp)
+	existing := createAccount(t, store, "user@example.com", true)
+
+	authURL, state, err := authSvc.StartOAuth(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := idp.authorize(authURL, "subject-1", existing.Email, false)
+
+	if _, err := authSvc.CompleteOAuth(ctx, testProvider, state, code); !errors.Is(err, svc.ErrOAuthFailed) {
+		t.Fatalf("err = %v, want ErrOAuthFailed", err)
+	}
+}
+
+func TestOAuthLinksExistingVerifiedAccount(t *testing.T) {
+	ctx := context.Background()
+	idp := newStubIdP(t)
+	authSvc, store := newTestAuthSvc(t, idp)
+	existing := createAccount(t, store, "user@example.com", true)
+
+	authURL, state, err := authSvc.StartOAuth(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := idp.authorize(authURL, "subject-1", "User@Example.com", true)
+	account, err := authSvc.CompleteOAuth(ctx, testProvider, state, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.ID != existing.ID {
+		t.Fatalf("signed in to account %q, want the existing %q", account.ID, existing.ID)
+	}
+	if account.PasswordHash == nil {
+		t.Error("linking to a verified account removed its password")
+	}
+
+	identities, err := store.Identities.ListByAccount(ctx, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(identities) != 1 || identities[0].Subject != "subject-1" {
+		t.Fatalf("identities = %+v, want the stub identity", identities)
+	}
+}
+
+func TestOAuthDropsIdentityOfErasedAccount(t *testing.T) {
+	ctx := context.Background()
+	idp := newStubIdP(t)
+	authSvc, store := newTestAuthSvc(t, idp)
+	err := store.Identities.Create(ctx, &model.Identity{
+		ID:        "identity-1",
+		AccountID: "erased-account",
+		Provider:  testProvider,
+		Subject:   "subject-1",
+		Email:     "user@example.com",
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signIn := func() (*model.Account, error) {
+		authURL, state, err := authSvc.StartOAuth(ctx, testProvider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return authSvc.CompleteOAuth(ctx, testProvider, state, idp.authorize(authURL, "subject-1", "user@example.com", true))
+	}
+	if _, err := signIn(); !errors.Is(err, svc.ErrOAuthFailed) {
+		t.Fatalf("err = %v, want ErrOAuthFailed", err)
+	}
+	if _, err := store.Identities.FindBySubject(ctx, testProvider, "subject-1"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("orphaned identity kept: err = %v", err)
+	}
+
+	account, err := signIn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.ID == "erased-account" {
+		t.Fatal("signed in to the erased account")
+	}
+}
+
+func TestOAuthTakesOverUnverifiedAccount(t *testing.T) {
+	ctx := context.Background()
+	idp := newStubIdP(t)
+	authSvc, store := newTestAuthSvc(t, idp)
+	existing := createAccount(t, store, "user@example.com", false)
+
+	authURL, state, err := authSvc.StartOAuth(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := idp.authorize(authURL, "subject-1", existing.Email, true)
+	account, err := authSvc.CompleteOAuth(ctx, testProvider, state, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.ID != existing.ID {
+		t.Fatalf("signed in to account %q, want the existing %q", account.ID, existing.ID)
+	}
+	if account.EmailVerifiedAt == nil {
+		t.Error("linked account is still unverified")
+	}
+	// Whoever set the password never proved they own the address.
+	if account.PasswordHash != nil {
+		t.Error("linked account kept the password of the unverified registration")
+	}
+}
+
+func TestOAuthGrantsConfiguredAdminRole(t *testing.T) {
+	ctx := context.Background()
+	idp := newStubIdP(t)
+	authSvc, store := newTestAuthSvc(t, idp)
+	// A password sign-up with the address does not prove ownership of it.
+	squatter := createAccount(t, store, "admin@example.com", false)
+	squatter.Roles = []model.Role{model.RoleUser}
+	if err := store.Accounts.Update(ctx, squatter); err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, state, err := authSvc.StartOAuth(ctx, testProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := idp.authorize(authURL, "subject-1", "admin@example.com", true)
+	account, err := authSvc.CompleteOAuth(ctx, testProvider, state, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []model.Role{model.RoleAdmin, model.RoleUser}; !slices.Equal(account.Roles, want) {
+		t.Errorf("roles = %v, want %v", account.Roles, want)
+	}
+}
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/auth/model"
+	"sync"
+	"time"
+)
+
+// ActionTokenRepo persists single-use tokens sent by email.
+type ActionTokenRepo interface {
+	Create(ctx context.Context, token *model.ActionToken) error
+	FindByID(ctx context.Context, id string) (*model.ActionToken, error)
+	// Consume atomically marks the token as used.
+	// It returns ErrConflict if the token had already been used.
+	Consume(ctx context.Context, id string, at time.Time) error
+	DeleteByAccount(ctx context.Context, accountID string) error
+}
+
+// MemoryActionTokenRepo is an in-memory ActionTokenRepo.
+type MemoryActionTokenRepo struct {
+	mu   sync.Mutex
+	byID map[string]model.ActionToken
+}
+
+func NewMemoryActionTokenRepo() *MemoryActionTokenRepo {
+	return &MemoryActionTokenRepo{
+		byID: make(map[string]model.ActionToken),
+	}
+}
+
+func (r *MemoryActionTokenRepo) Create(_ context.Context, token *model.ActionToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[token.ID]; ok {
+		return ErrDuplicate
+	}
+	r.byID[token.ID] = *token
+	return nil
+}
+
+func (r *MemoryActionTokenRepo) FindByID(_ context.Context, id string) (*model.ActionToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &token, nil
+}
+
+func (r *MemoryActionTokenRepo) Consume(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if token.UsedAt != nil {
+		return ErrConflict
+	}
+	token.UsedAt = &at
+	r.byID[id] = token
+	return nil
+}
+
+func (r *MemoryActionTokenRepo) DeleteByAccount(_ context.Context, accountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.byID {
+		if token.AccountID == accountID {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
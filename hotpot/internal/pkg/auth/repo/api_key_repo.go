@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/auth/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// APIKeyRepo persists API keys.
+type APIKeyRepo interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	FindByID(ctx context.Context, id string) (*model.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, error)
+	// ListByAccount returns the keys of the account, newest first.
+	ListByAccount(ctx context.Context, accountID string) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id string, at time.Time) error
+	Touch(ctx context.Context, id string, at time.Time) error
+	DeleteByAccount(ctx context.Context, accountID string) error
+}
+
+// MemoryAPIKeyRepo is an in-memory APIKeyRepo.
+type MemoryAPIKeyRepo struct {
+	mu       sync.RWMutex
+	byID     map[string]model.APIKey
+	byPrefix map[string]string
+}
+
+func NewMemoryAPIKeyRepo() *MemoryAPIKeyRepo {
+	return &MemoryAPIKeyRepo{
+		byID:     make(map[string]model.APIKey),
+		byPrefix: make(map[string]string),
+	}
+}
+
+func (r *MemoryAPIKeyRepo) Create(_ context.Context, key *model.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byPrefix[key.Prefix]; ok {
+		return ErrDuplicate
+	}
+	r.byID[key.ID] = *key
+	r.byPrefix[key.Prefix] = key.ID
+	return nil
+}
+
+func (r *MemoryAPIKeyRepo) FindByID(_ context.Context, id string) (*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &key, nil
+}
+
+func (r *MemoryAPIKeyRepo) FindByPrefix(_ context.Context, prefix string) (*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byPrefix[prefix]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	key := r.byID[id]
+	return &key, nil
+}
+
+func (r *MemoryAPIKeyRepo) ListByAccount(_ context.Context, accountID string) ([]model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []model.APIKey
+	for _, key := range r.byID {
+		if key.AccountID == accountID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+func (r *MemoryAPIKeyRepo) Revoke(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+		r.byID[id] = key
+	}
+	return nil
+}
+
+func (r *MemoryAPIKeyRepo) Touch(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	key.LastUsedAt = &at
+	r.byID[id] = key
+	return nil
+}
+
+func (r *MemoryAPIKeyRepo) DeleteByAccount(_ context.Context, accountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, key := range r.byID {
+		if key.AccountID == accountID {
+			delete(r.byPrefix, key.Prefix)
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/auth/model"
+	"slices"
+	"sync"
+	"time"
+)
+
+// AuditFilter narrows down an audit log query. Zero fields match everything.
+type AuditFilter struct {
+	Type      model.AuditEventType
+	ActorID   string
+	SubjectID string
+	Outcome   model.AuditOutcome
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+func (f AuditFilter) matches(e *model.AuditEvent) bool {
+	return (f.Type == "" || e.Type == f.Type) &&
+		(f.ActorID == "" || e.ActorID == f.ActorID) &&
+		(f.SubjectID == "" || e.SubjectID == f.SubjectID) &&
+		(f.Outcome == "" || e.Outcome == f.Outcome) &&
+		(f.From.IsZero() || !e.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || e.CreatedAt.Before(f.To))
+}
+
+// AuditRepo persists audit events. Events can only be appended, never changed.
+type AuditRepo interface {
+	Append(ctx context.Context, event *model.AuditEvent) error
+	// List returns a page of matching events, newest first, and the total
+	// number of matching events.
+	List(ctx context.Context, filter AuditFilter) ([]model.AuditEvent, int, error)
+	// Anonymize strips personal data from the events of a deleted account,
+	// keeping the events themselves for compliance. It is the only change
+	// ever made to stored events.
+	Anonymize(ctx context.Context, accountID string) error
+}
+
+// MemoryAuditRepo is an in-memory AuditRepo.
+type MemoryAuditRepo struct {
+	mu     sync.RWMutex
+	events []model.AuditEvent
+}
+
+func NewMemoryAuditRepo() *MemoryAuditRepo {
+	return &MemoryAuditRepo{}
+}
+
+func (r *MemoryAuditRepo) Append(_ context.Context, event *model.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *MemoryAuditRepo) List(_ context.Context, filter AuditFilter) ([]model.AuditEvent, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := []model.AuditEvent{}
+	total := 0
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if !filter.matches(&r.events[i]) {
+			continue
+		}
+		if total >= filter.Offset && len(page) < filter.Limit {
+			page = append(page, r.events[i])
+		}
+		total++
+	}
+	return page, total, nil
+}
+
+func (r *MemoryAuditRepo) Anonymize(_ context.Context, accountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.events {
+		e := &r.events[i]
+		if e.ActorID == accountID || e.SubjectID == accountID {
+			e.IP = ""
+			e.UserAgent = ""
+			e.Details = withoutPersonalDetails(e.Details)
+		}
+	}
+	return nil
+}
+
+// personalDetails are the audit detail keys that can identify a person.
+var personalDetails = []string{"email", "device"}
+
+// withoutPersonalDetails returns a copy of details without personalDetails.
+func withoutPersonalDetails(details map[string]string) map[string]string {
+	if details == nil {
+		return nil
+	}
+	clean := make(map[string]string, len(details))
+	for k, v := range details {
+		if !slices.Contains(personalDetails, k) {
+			clean[k] = v
+		}
+	}
+	return clean
+}
@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/auth/model"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotFound  = errors.New("record not found")
+	ErrDuplicate = errors.New("record already exists")
+	ErrConflict  = errors.New("record was modified concurrently")
+)
+
+// Store groups the repositories used by the auth module.
+type Store struct {
+	Accounts      AccountRepo
+	Sessions      SessionRepo
+	RefreshTokens RefreshTokenRepo
+	Identities    IdentityRepo
+	OAuthStates   OAuthStateRepo
+	ActionTokens  ActionTokenRepo
+	APIKeys       APIKeyRepo
+	Audit         AuditRepo
+}
+
+// NewMemoryStore returns a Store backed entirely by in-memory repositories.
+func NewMemoryStore() *Store {
+	return &Store{
+		Accounts:      NewMemoryAccountRepo(),
+		Sessions:      NewMemorySessionRepo(),
+		RefreshTokens: NewMemoryRefreshTokenRepo(),
+		Identities:    NewMemoryIdentityRepo(),
+		OAuthStates:   NewMemoryOAuthStateRepo(),
+		ActionTokens:  NewMemoryActionTokenRepo(),
+		APIKeys:       NewMemoryAPIKeyRepo(),
+		Audit:         NewMemoryAuditRepo(),
+	}
+}
+
+// AccountRepo persists user accounts and their credentials.
+type AccountRepo interface {
+	Create(ctx context.Context, account *model.Account) error
+	Update(ctx context.Context, account *model.Account) error
+	FindByID(ctx context.Context, id string) (*model.Account, error)
+	FindByEmail(ctx context.Context, email string) (*model.Account, error)
+	// Search returns the accounts matching the query, in no particular order.
+	Search(ctx context.Context, q model.AccountQuery) ([]model.Account, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryAccountRepo is an in-memory AccountRepo.
+type MemoryAccountRepo struct {
+	mu      sync.RWMutex
+	byID    map[string]model.Account
+	byEmail map[string]string
+}
+
+func NewMemoryAccountRepo() *MemoryAccountRepo {
+	return &MemoryAccountRepo{
+		byID:    make(map[string]model.Account),
+		byEmail: make(map[string]string),
+	}
+}
+
+func (r *MemoryAccountRepo) Create(_ context.Context, account *model.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byEmail[account.Email]; ok {
+		return ErrDuplicate
+	}
+	if _, ok := r.byID[account.ID]; ok {
+		return ErrDuplicate
+	}
+	r.byID[account.ID] = *account
+	r.byEmail[account.Email] = account.ID
+	return nil
+}
+
+func (r *MemoryAccountRepo) Update(_ context.Context, account *model.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.byID[account.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if old.Email != account.Email {
+		if _, taken := r.byEmail[account.Email]; taken {
+			return ErrDuplicate
+		}
+		delete(r.byEmail, old.Email)
+		r.byEmail[account.Email] = account.ID
+	}
+	r.byID[account.ID] = *account
+	return nil
+}
+
+func (r *MemoryAccountRepo) FindByID(_ context.Context, id string) (*model.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	account, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &account, nil
+}
+
+func (r *MemoryAccountRepo) FindByEmail(_ context.Context, email string) (*model.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byEmail[email]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	account := r.byID[id]
+	return &account, nil
+}
+
+func (r *MemoryAccountRepo) Search(_ context.Context, q model.AccountQuery) ([]model.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	email := strings.ToLower(q.Email)
+	var accounts []model.Account
+	for _, a := range r.byID {
+		switch {
+		case email != "" && !strings.Contains(strings.ToLower(a.Email), email),
+			q.Role != "" && !slices.Contains(a.Roles, q.Role),
+			q.Verified != nil && *q.Verified != (a.EmailVerifiedAt != nil),
+			!q.CreatedFrom.IsZero() && a.CreatedAt.Before(q.CreatedFrom),
+			!q.CreatedTo.IsZero() && !a.CreatedAt.Before(q.CreatedTo):
+			continue
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, nil
+}
+
+func (r *MemoryAccountRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(r.byEmail, account.Email)
+	delete(r.byID, id)
+	return nil
+}
@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/auth/model"
+	"sync"
+	"time"
+)
+
+// IdentityRepo persists links between accounts and external identities.
+type IdentityRepo interface {
+	Create(ctx context.Context, identity *model.Identity) error
+	FindBySubject(ctx context.Context, provider, subject string) (*model.Identity, error)
+	ListByAccount(ctx context.Context, accountID string) ([]model.Identity, error)
+	DeleteByAccount(ctx context.Context, accountID string) error
+}
+
+// OAuthStateRepo keeps pending authorization-code flows.
+type OAuthStateRepo interface {
+	Create(ctx context.Context, state *model.OAuthState) error
+	// Take returns the state and deletes it, so that every state is used at most once.
+	Take(ctx context.Context, state string) (*model.OAuthState, error)
+}
+
+// MemoryIdentityRepo is an in-memory IdentityRepo.
+type MemoryIdentityRepo struct {
+	mu        sync.RWMutex
+	bySubject map[string]model.Identity
+}
+
+func NewMemoryIdentityRepo() *MemoryIdentityRepo {
+	return &MemoryIdentityRepo{
+		bySubject: make(map[string]model.Identity),
+	}
+}
+
+func (r *MemoryIdentityRepo) Create(_ context.Context, identity *model.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := identity.Provider + "|" + identity.Subject
+	if _, ok := r.bySubject[key]; ok {
+		return ErrDuplicate
+	}
+	r.bySubject[key] = *identity
+	return nil
+}
+
+func (r *MemoryIdentityRepo) FindBySubject(_ context.Context, provider, subject string) (*model.Identity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	identity, ok := r.bySubject[provider+"|"+subject]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &identity, nil
+}
+
+func (r *MemoryIdentityRepo) ListByAccount(_ context.Context, accountID string) ([]model.Identity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var identities []model.Identity
+	for _, identity := range r.bySubject {
+		if identity.AccountID == accountID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *MemoryIdentityRepo) DeleteByAccount(_ context.Context, accountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, identity := range r.bySubject {
+		if identity.AccountID == accountID {
+			delete(r.bySubject, key)
+		}
+	}
+	return nil
+}
+
+// MemoryOAuthStateRepo is an in-memory OAuthStateRepo.
+type MemoryOAuthStateRepo struct {
+	mu     sync.Mutex
+	states map[string]model.OAuthState
+}
+
+func NewMemoryOAuthStateRepo() *MemoryOAuthStateRepo {
+	return &MemoryOAuthStateRepo{
+		states: make(map[string]model.OAuthState),
+	}
+}
+
+func (r *MemoryOAuthStateRepo) Create(_ context.Context, state *model.OAuthState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Drop abandoned flows so the map does not grow without bound.
+	now := time.Now()
+	for key, s := range r.states {
+		if now.After(s.ExpiresAt) {
+			delete(r.states, key)
+		}
+	}
+
+	if _, ok := r.states[state.State]; ok {
+		return ErrDuplicate
+	}
+	r.states[state.State] = *state
+	return nil
+}
+
+func (r *MemoryOAuthStateRepo) Take(_ context.Context, state string) (*model.OAuthState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.states[state]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(r.states, state)
+	return &s, nil
+}
@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/auth/model"
+	"sync"
+	"time"
+)
+
+// RefreshTokenRepo persists issued refresh tokens.
+type RefreshTokenRepo interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
+	// MarkRotated atomically flags the token as exchanged.
+	// It returns ErrConflict if the token had already been rotated.
+	MarkRotated(ctx context.Context, id string, at time.Time) error
+	DeleteByAccount(ctx context.Context, accountID string) error
+}
+
+// MemoryRefreshTokenRepo is an in-memory RefreshTokenRepo.
+type MemoryRefreshTokenRepo struct {
+	mu     sync.RWMutex
+	byID   map[string]model.RefreshToken
+	byHash map[string]string
+}
+
+func NewMemoryRefreshTokenRepo() *MemoryRefreshTokenRepo {
+	return &MemoryRefreshTokenRepo{
+		byID:   make(map[string]model.RefreshToken),
+		byHash: make(map[string]string),
+	}
+}
+
+func (r *MemoryRefreshTokenRepo) Create(_ context.Context, token *model.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byHash[token.TokenHash]; ok {
+		return ErrDuplicate
+	}
+	r.byID[token.ID] = *token
+	r.byHash[token.TokenHash] = token.ID
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepo) FindByHash(_ context.Context, hash string) (*model.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byHash[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	token := r.byID[id]
+	return &token, nil
+}
+
+func (r *MemoryRefreshTokenRepo) MarkRotated(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if token.RotatedAt != nil {
+		return ErrConflict
+	}
+	token.RotatedAt = &at
+	r.byID[id] = token
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepo) DeleteByAccount(_ context.Context, accountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.byID {
+		if token.AccountID == accountID {
+			delete(r.byHash, token.TokenHash)
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
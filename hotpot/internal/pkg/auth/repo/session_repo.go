@@ -0,0 +1,128 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/auth/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionRepo persists refresh token families.
+type SessionRepo interface {
+	Create(ctx context.Context, session *model.Session) error
+	FindByID(ctx context.Context, id string) (*model.Session, error)
+	// ListActiveByAccount returns the account's unrevoked sessions, most recently seen first.
+	ListActiveByAccount(ctx context.Context, accountID string) ([]model.Session, error)
+	// Touch records that the session was used by the given client.
+	Touch(ctx context.Context, id, ip, userAgent string, at time.Time) error
+	Revoke(ctx context.Context, id string, at time.Time) error
+	// RevokeByAccount revokes every active session of the account.
+	RevokeByAccount(ctx context.Context, accountID string, at time.Time) error
+	DeleteByAccount(ctx context.Context, accountID string) error
+}
+
+// MemorySessionRepo is an in-memory SessionRepo.
+type MemorySessionRepo struct {
+	mu   sync.RWMutex
+	byID map[string]model.Session
+}
+
+func NewMemorySessionRepo() *MemorySessionRepo {
+	return &MemorySessionRepo{
+		byID: make(map[string]model.Session),
+	}
+}
+
+func (r *MemorySessionRepo) Create(_ context.Context, session *model.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[session.ID]; ok {
+		return ErrDuplicate
+	}
+	r.byID[session.ID] = *session
+	return nil
+}
+
+func (r *MemorySessionRepo) FindByID(_ context.Context, id string) (*model.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+func (r *MemorySessionRepo) ListActiveByAccount(_ context.Context, accountID string) ([]model.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sessions []model.Session
+	for _, session := range r.byID {
+		if session.AccountID == accountID && session.RevokedAt == nil {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+func (r *MemorySessionRepo) Touch(_ context.Context, id, ip, userAgent string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	session.IP = ip
+	session.UserAgent = userAgent
+	session.LastSeenAt = at
+	r.byID[id] = session
+	return nil
+}
+
+func (r *MemorySessionRepo) Revoke(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if session.RevokedAt == nil {
+		session.RevokedAt = &at
+		r.byID[id] = session
+	}
+	return nil
+}
+
+func (r *MemorySessionRepo) RevokeByAccount(_ context.Context, accountID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.byID {
+		if session.AccountID == accountID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			r.byID[id] = session
+		}
+	}
+	return nil
+}
+
+func (r *MemorySessionRepo) DeleteByAccount(_ context.Context, accountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.byID {
+		if session.AccountID == accountID {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
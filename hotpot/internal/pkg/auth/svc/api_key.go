@@ -0,0 +1,162 @@
+package svc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+const (
+	// API keys look like hp_<8 char id>_<secret>; "hp_<id>" is the visible prefix.
+	apiKeyMarker    = "hp_"
+	apiKeyIDLen     = 8
+	apiKeyPrefixLen = len(apiKeyMarker) + apiKeyIDLen
+	// apiKeyTouchInterval limits how often LastUsedAt is written for busy keys.
+	apiKeyTouchInterval = time.Minute
+)
+
+// CreateAPIKey issues a new API key for the owner. The plain key is returned
+// only once; afterwards only its prefix can be shown.
+func (svc *AuthSvc) CreateAPIKey(
+	ctx context.Context,
+	ownerID, createdBy, name string,
+	scopes []model.Scope,
+	ttl time.Duration,
+) (*model.APIKey, string, error) {
+	if _, err := svc.store.Accounts.FindByID(ctx, ownerID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, "", ErrAccountNotFound
+		}
+		return nil, "", err
+	}
+
+	id, err := newAPIKeyID()
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	prefix := apiKeyMarker + id
+	plain := prefix + "_" + secret
+
+	now := time.Now().UTC()
+	key := &model.APIKey{
+		ID:        uuid.NewString(),
+		AccountID: ownerID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashToken(plain),
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		exp := now.Add(ttl)
+		key.ExpiresAt = &exp
+	}
+	if err := svc.store.APIKeys.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	svc.logger.Info("api key created", "account_id", ownerID, "api_key_id", key.ID, "created_by", createdBy)
+	svc.RecordAudit(ctx, model.AuditAPIKeyCreate, ownerID, model.AuditSuccess,
+		map[string]string{"api_key_id": key.ID, "prefix": key.Prefix})
+	return key, plain, nil
+}
+
+// ListAPIKeys returns every key of the owner, including revoked ones.
+func (svc *AuthSvc) ListAPIKeys(ctx context.Context, ownerID string) ([]model.APIKey, error) {
+	return svc.store.APIKeys.ListByAccount(ctx, ownerID)
+}
+
+// RevokeAPIKey revokes a key. When ownerID is not empty the key must belong
+// to that account; admins pass an empty ownerID to revoke any key.
+func (svc *AuthSvc) RevokeAPIKey(ctx context.Context, id, ownerID string) error {
+	key, err := svc.store.APIKeys.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+	if ownerID != "" && key.AccountID != ownerID {
+		return ErrAPIKeyNotFound
+	}
+
+	if err := svc.store.APIKeys.Revoke(ctx, id, time.Now().UTC()); err != nil {
+		return err
+	}
+	svc.logger.Info("api key revoked", "account_id", key.AccountID, "api_key_id", key.ID)
+	svc.RecordAudit(ctx, model.AuditAPIKeyRevoke, key.AccountID, model.AuditSuccess,
+		map[string]string{"api_key_id": key.ID, "prefix": key.Prefix})
+	return nil
+}
+
+// AuthenticateAPIKey verifies a plain API key and returns its caller, who
+// acts with the owner's roles limited to the key's scopes.
+func (svc *AuthSvc) AuthenticateAPIKey(ctx context.Context, plain string) (*Principal, error) {
+	if len(plain) <= apiKeyPrefixLen+1 || !strings.HasPrefix(plain, apiKeyMarker) {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := svc.store.APIKeys.FindByPrefix(ctx, plain[:apiKeyPrefixLen])
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashToken(plain))) != 1 ||
+		key.RevokedAt != nil ||
+		(key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, ErrInvalidToken
+	}
+
+	account, err := svc.store.Accounts.FindByID(ctx, key.AccountID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := svc.store.APIKeys.Touch(ctx, key.ID, now); err != nil {
+			svc.logger.Error("api key touch failed", "api_key_id", key.ID, "error", err)
+		}
+	}
+
+	return &Principal{
+		UserID:        account.ID,
+		APIKeyID:      key.ID,
+		EmailVerified: account.EmailVerifiedAt != nil,
+		Roles:         account.Roles,
+		Scopes:        key.Scopes,
+	}, nil
+}
+
+func newAPIKeyID() (string, error) {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+	b := make([]byte, apiKeyIDLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,64 @@
+package svc
+
+import (
+	"context"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type auditActorKey struct{}
+
+// auditActor is who makes a request, and from where.
+type auditActor struct {
+	id     string
+	client ClientInfo
+}
+
+// WithAuditActor attaches the calling account, which may be empty for
+// anonymous requests, and its client to ctx so audit events can name them.
+func WithAuditActor(ctx context.Context, actorID string, client ClientInfo) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, auditActor{id: actorID, client: client})
+}
+
+// RecordAudit appends an event about subjectID to the audit log, attributed to
+// the actor attached with WithAuditActor. Other modules use it to record access
+// to sensitive data. Failures are logged rather than returned, so a broken
+// audit store does not take the app down with it.
+func (svc *AuthSvc) RecordAudit(
+	ctx context.Context,
+	eventType model.AuditEventType,
+	subjectID string,
+	outcome model.AuditOutcome,
+	details map[string]string,
+) {
+	actor, _ := ctx.Value(auditActorKey{}).(auditActor)
+	event := &model.AuditEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		ActorID:   actor.id,
+		SubjectID: subjectID,
+		IP:        actor.client.IP,
+		UserAgent: actor.client.UserAgent,
+		Outcome:   outcome,
+		Details:   details,
+		CreatedAt: time.Now().UTC(),
+	}
+	// Logins are made by the account logging in, before anyone is
+	// authenticated. A failed login proves nothing about who made it.
+	if event.ActorID == "" && eventType == model.AuditLogin && outcome == model.AuditSuccess {
+		event.ActorID = subjectID
+	}
+
+	if err := svc.store.Audit.Append(ctx, event); err != nil {
+		svc.logger.Error("append audit event failed", "type", eventType, "subject_id", subjectID, "error", err)
+	}
+}
+
+// ListAudit returns a page of audit events, newest first, and the number of
+// events matching the filter.
+func (svc *AuthSvc) ListAudit(ctx context.Context, filter repo.AuditFilter) ([]model.AuditEvent, int, error) {
+	return svc.store.Audit.List(ctx, filter)
+}
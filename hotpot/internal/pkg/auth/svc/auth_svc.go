@@ -2,19 +2,231 @@ package svc
 
 import (
 	"context"
+	"errors"
+	"hotpot/internal/core/cfg"
+	"hotpot/internal/core/utils/mailer"
+	"hotpot/internal/core/utils/ratelimit"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
 	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrAccountNotFound    = errors.New("account not found")
+	ErrPasswordTooLong    = errors.New("password must not be longer than 72 bytes")
 )
 
+// maxPasswordBytes is the longest password bcrypt hashes. Validation counts
+// characters, which may take several bytes each.
+const maxPasswordBytes = 72
+
+// dummyHash is compared against when the account does not exist, so that
+// unknown emails take as long to reject as wrong passwords.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("hotpot-dummy-password"), bcrypt.DefaultCost)
+
 type AuthSvc struct {
-	logger *slog.Logger
+	logger    *slog.Logger
+	cfg       *cfg.Config
+	store     *repo.Store
+	mailer    mailer.Mailer
+	providers map[string]OAuthProvider
+
+	// lockout throttles failed logins per email and failed second factors per account.
+	lockout *ratelimit.Lockout
+	// resetLimiter caps the password reset emails sent to one address.
+	resetLimiter *ratelimit.Limiter
+	// mfaAttempts caps the codes tried with one MFA token.
+	mfaAttempts *ratelimit.Limiter
 }
 
-func NewAuthService(logger *slog.Logger) *AuthSvc {
-	return &AuthSvc{
-		logger: logger,
+func NewAuthService(
+	logger *slog.Logger,
+	config *cfg.Config,
+	store *repo.Store,
+	mailer mailer.Mailer,
+	limits ratelimit.Store,
+	providers []OAuthProvider,
+) *AuthSvc {
+	svc := &AuthSvc{
+		logger:    logger,
+		cfg:       config,
+		store:     store,
+		mailer:    mailer,
+		providers: make(map[string]OAuthProvider, len(providers)),
+		lockout: ratelimit.NewLockout(limits, "auth-lockout",
+			config.LockoutThreshold, config.LockoutBase, config.LockoutMax, config.LockoutWindow),
+		resetLimiter: ratelimit.New(limits, "auth-reset", config.PasswordResetLimit, config.PasswordResetWindow),
+		mfaAttempts:  ratelimit.New(limits, "auth-mfa-token", mfaTokenAttempts, mfaTokenTTL),
+	}
+	for _, p := range providers {
+		svc.providers[p.Name()] = p
 	}
+	return svc
 }
 
 func (svc *AuthSvc) Ping(_ context.Context) (bool, error) {
 	return true, nil
 }
+
+// Register creates a new account with a bcrypt-hashed password.
+func (svc *AuthSvc) Register(ctx context.Context, email, password string) (*model.Account, error) {
+	if len(password) > maxPasswordBytes {
+		return nil, ErrPasswordTooLong
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	account := &model.Account{
+		ID:           uuid.NewString(),
+		Email:        normalizeEmail(email),
+		PasswordHash: hash,
+		Roles:        []model.Role{model.RoleUser},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := svc.store.Accounts.Create(ctx, account); err != nil {
+		if errors.Is(err, repo.ErrDuplicate) {
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+
+	svc.logger.Info("account registered", "account_id", account.ID)
+
+	// The account is usable right away; a failed email can be re-sent later.
+	if err := svc.SendEmailVerification(ctx, account.ID); err != nil {
+		svc.logger.Error("send verification email failed", "account_id", account.ID, "error", err)
+	}
+	return account, nil
+}
+
+// Login checks the email and password pair and returns the matching account.
+// Repeated failures lock the email out and are reported as *ratelimit.ExceededError.
+func (svc *AuthSvc) Login(ctx context.Context, email, password string) (*model.Account, error) {
+	email = normalizeEmail(email)
+	lockKey := "login:" + email
+	if err := svc.checkLockout(ctx, lockKey); err != nil {
+		svc.RecordAudit(ctx, model.AuditLogin, "", model.AuditFailure,
+			map[string]string{"email": email, "reason": "locked"})
+		return nil, err
+	}
+
+	account, err := svc.store.Accounts.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	if account == nil {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+	}
+	if account == nil || bcrypt.CompareHashAndPassword(account.PasswordHash, []byte(password)) != nil {
+		subjectID := ""
+		if account != nil {
+			subjectID = account.ID
+		}
+		svc.RecordAudit(ctx, model.AuditLogin, subjectID, model.AuditFailure,
+			map[string]string{"email": email, "reason": "invalid_credentials"})
+		if err := svc.recordFailure(ctx, lockKey); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	svc.clearFailures(ctx, lockKey)
+	return account, nil
+}
+
+// Account returns the account with the given ID.
+func (svc *AuthSvc) Account(ctx context.Context, id string) (*model.Account, error) {
+	return svc.store.Accounts.FindByID(ctx, id)
+}
+
+// AccountExists reports whether an account with the given ID exists.
+func (svc *AuthSvc) AccountExists(ctx context.Context, id string) (bool, error) {
+	_, err := svc.store.Accounts.FindByID(ctx, id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// AccountEmail returns the email address of the account.
+func (svc *AuthSvc) AccountEmail(ctx context.Context, id string) (string, error) {
+	account, err := svc.store.Accounts.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return "", ErrAccountNotFound
+		}
+		return "", err
+	}
+	return account.Email, nil
+}
+
+// SearchAccounts returns the accounts matching the query, so other modules
+// can build user directories without seeing credentials.
+func (svc *AuthSvc) SearchAccounts(ctx context.Context, q model.AccountQuery) ([]model.AccountSummary, error) {
+	accounts, err := svc.store.Accounts.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]model.AccountSummary, len(accounts))
+	for i, a := range accounts {
+		summaries[i] = model.AccountSummary{
+			ID:            a.ID,
+			Email:         a.Email,
+			Roles:         a.Roles,
+			EmailVerified: a.EmailVerifiedAt != nil,
+			CreatedAt:     a.CreatedAt,
+		}
+	}
+	return summaries, nil
+}
+
+// checkLockout returns the lockout error for key, if any. Store failures are
+// logged and ignored so that a broken limiter does not lock everybody out.
+func (svc *AuthSvc) checkLockout(ctx context.Context, key string) error {
+	err := svc.lockout.Check(ctx, key)
+	var exceeded *ratelimit.ExceededError
+	if err != nil && !errors.As(err, &exceeded) {
+		svc.logger.Error("lockout check failed", "error", err)
+		return nil
+	}
+	return err
+}
+
+// recordFailure counts a failed attempt and returns the lockout error if it
+// locked the key out.
+func (svc *AuthSvc) recordFailure(ctx context.Context, key string) error {
+	err := svc.lockout.Fail(ctx, key)
+	var exceeded *ratelimit.ExceededError
+	if err != nil && !errors.As(err, &exceeded) {
+		svc.logger.Error("lockout update failed", "error", err)
+		return nil
+	}
+	if exceeded != nil {
+		svc.logger.Warn("too many failed attempts, locking out", "key", key, "retry_after", exceeded.RetryAfter)
+	}
+	return err
+}
+
+func (svc *AuthSvc) clearFailures(ctx context.Context, key string) {
+	if err := svc.lockout.Reset(ctx, key); err != nil {
+		svc.logger.Error("lockout reset failed", "error", err)
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
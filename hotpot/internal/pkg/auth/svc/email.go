@@ -0,0 +1,179 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotpot/internal/core/utils/mailer"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrAlreadyVerified = errors.New("email is already verified")
+
+// SendEmailVerification emails the account a link that proves ownership of its address.
+func (svc *AuthSvc) SendEmailVerification(ctx context.Context, accountID string) error {
+	account, err := svc.store.Accounts.FindByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+
+	token, err := svc.issueActionToken(ctx, account, audienceEmailVerification, svc.cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return svc.mailer.Send(ctx, mailer.Message{
+		To:      account.Email,
+		Subject: "Confirm your HotPot email",
+		Body: fmt.Sprintf("Welcome to HotPot!\n\nConfirm your email address by opening this link:\n%s\n\nThe link expires in %s.\n",
+			svc.appLink("/verify-email", token), svc.cfg.EmailVerificationTTL),
+	})
+}
+
+// VerifyEmail marks the account behind a verification token as verified.
+func (svc *AuthSvc) VerifyEmail(ctx context.Context, token string) error {
+	account, err := svc.consumeActionToken(ctx, token, audienceEmailVerification)
+	if err != nil {
+		return err
+	}
+	if account.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	account.EmailVerifiedAt = &now
+	account.UpdatedAt = now
+	svc.grantConfiguredRoles(account)
+	return svc.store.Accounts.Update(ctx, account)
+}
+
+// RequestPasswordReset emails a reset link if the address belongs to an account.
+// Unknown addresses are ignored so the endpoint cannot be used to probe for accounts.
+func (svc *AuthSvc) RequestPasswordReset(ctx context.Context, email string) error {
+	account, err := svc.store.Accounts.FindByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if err := svc.resetLimiter.Allow(ctx, account.ID); err != nil {
+		// Stay silent towards the caller; the user already has recent emails.
+		svc.logger.Warn("password reset email throttled", "account_id", account.ID, "error", err)
+		return nil
+	}
+
+	token, err := svc.issueActionToken(ctx, account, audiencePasswordReset, svc.cfg.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return svc.mailer.Send(ctx, mailer.Message{
+		To:      account.Email,
+		Subject: "Reset your HotPot password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your HotPot account.\n\nChoose a new password here:\n%s\n\nThe link expires in %s. If it wasn't you, ignore this email.\n",
+			svc.appLink("/reset-password", token), svc.cfg.PasswordResetTTL),
+	})
+}
+
+// ResetPassword sets a new password using a reset token and signs the account
+// out everywhere. Receiving the email also proves ownership of the address.
+func (svc *AuthSvc) ResetPassword(ctx context.Context, token, password string) error {
+	// Checked first, so that the token is not spent on a password that
+	// cannot be hashed.
+	if len(password) > maxPasswordBytes {
+		return ErrPasswordTooLong
+	}
+	account, err := svc.consumeActionToken(ctx, token, audiencePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	account.PasswordHash = hash
+	if account.EmailVerifiedAt == nil {
+		account.EmailVerifiedAt = &now
+		svc.grantConfiguredRoles(account)
+	}
+	account.UpdatedAt = now
+	if err := svc.store.Accounts.Update(ctx, account); err != nil {
+		return err
+	}
+	svc.clearFailures(ctx, "login:"+account.Email)
+	svc.RecordAudit(ctx, model.AuditPasswordChange, account.ID, model.AuditSuccess, map[string]string{"method": "reset"})
+	return svc.LogoutAll(ctx, account.ID)
+}
+
+// issueActionToken stores a single-use token record and returns it as a signed JWT.
+func (svc *AuthSvc) issueActionToken(ctx context.Context, account *model.Account, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now().UTC()
+	record := &model.ActionToken{
+		ID:        uuid.NewString(),
+		AccountID: account.ID,
+		Purpose:   purpose,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := svc.store.ActionTokens.Create(ctx, record); err != nil {
+		return "", err
+	}
+
+	claims := svc.registeredClaims(account.ID, purpose, now, record.ExpiresAt)
+	claims.ID = record.ID
+	return svc.signJWT(claims)
+}
+
+// consumeActionToken verifies a token from issueActionToken, marks it used
+// and returns its account.
+func (svc *AuthSvc) consumeActionToken(ctx context.Context, token, purpose string) (*model.Account, error) {
+	claims := &jwt.RegisteredClaims{}
+	if err := svc.parseJWT(token, claims, purpose); err != nil {
+		return nil, err
+	}
+
+	record, err := svc.store.ActionTokens.FindByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if record.Purpose != purpose || record.AccountID != claims.Subject {
+		return nil, ErrInvalidToken
+	}
+	if err := svc.store.ActionTokens.Consume(ctx, record.ID, time.Now().UTC()); err != nil {
+		if errors.Is(err, repo.ErrConflict) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	account, err := svc.store.Accounts.FindByID(ctx, record.AccountID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+func (svc *AuthSvc) appLink(path, token string) string {
+	return svc.cfg.AppBaseURL + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,36 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+)
+
+// EraseUserData deletes the account with its credentials, devices, API keys
+// and linked identities. Audit events are kept for compliance but stripped
+// of personal data. Erasing an account that is already gone succeeds.
+func (svc *AuthSvc) EraseUserData(ctx context.Context, userID string) error {
+	for _, deleteByAccount := range []func(context.Context, string) error{
+		svc.store.APIKeys.DeleteByAccount,
+		svc.store.RefreshTokens.DeleteByAccount,
+		svc.store.Sessions.DeleteByAccount,
+		svc.store.ActionTokens.DeleteByAccount,
+		svc.store.Identities.DeleteByAccount,
+	} {
+		if err := deleteByAccount(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := svc.store.Accounts.Delete(ctx, userID); err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return err
+	}
+	if err := svc.store.Audit.Anonymize(ctx, userID); err != nil {
+		return err
+	}
+
+	svc.RecordAudit(ctx, model.AuditAccountDelete, userID, model.AuditSuccess, nil)
+	svc.logger.Info("account erased", "account_id", userID)
+	return nil
+}
@@ -0,0 +1,170 @@
+package svc
+
+import (
+	"context"
+	"hotpot/internal/core/utils/userdata"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"math"
+	"sort"
+	"time"
+)
+
+type accountRecord struct {
+	ID              string       `json:"id"`
+	Email           string       `json:"email"`
+	Roles           []model.Role `json:"roles"`
+	HasPassword     bool         `json:"has_password"`
+	EmailVerifiedAt *time.Time   `json:"email_verified_at"`
+	MFAEnabledAt    *time.Time   `json:"mfa_enabled_at"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+type sessionRecord struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type apiKeyRecord struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	Scopes     []model.Scope `json:"scopes"`
+	CreatedAt  time.Time     `json:"created_at"`
+	ExpiresAt  *time.Time    `json:"expires_at"`
+	LastUsedAt *time.Time    `json:"last_used_at"`
+	RevokedAt  *time.Time    `json:"revoked_at"`
+}
+
+type identityRecord struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type auditRecord struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	ActorID   string            `json:"actor_id"`
+	SubjectID string            `json:"subject_id"`
+	IP        string            `json:"ip"`
+	UserAgent string            `json:"user_agent"`
+	Outcome   string            `json:"outcome"`
+	Details   map[string]string `json:"details"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// ExportUserData returns the account, its devices, API keys, linked
+// identities and the audit events it performed or was subject to.
+// Password hashes, secrets and token hashes are left out.
+func (svc *AuthSvc) ExportUserData(ctx context.Context, userID string) ([]userdata.Dataset, error) {
+	account, err := svc.store.Accounts.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	accounts := []accountRecord{{
+		ID:              account.ID,
+		Email:           account.Email,
+		Roles:           account.Roles,
+		HasPassword:     len(account.PasswordHash) > 0,
+		EmailVerifiedAt: account.EmailVerifiedAt,
+		MFAEnabledAt:    account.TOTPEnabledAt,
+		CreatedAt:       account.CreatedAt,
+		UpdatedAt:       account.UpdatedAt,
+	}}
+
+	list, err := svc.store.Sessions.ListActiveByAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]sessionRecord, len(list))
+	for i, s := range list {
+		sessions[i] = sessionRecord{
+			ID:         s.ID,
+			DeviceName: s.DeviceName,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+		}
+	}
+
+	keys, err := svc.store.APIKeys.ListByAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	apiKeys := make([]apiKeyRecord, len(keys))
+	for i, k := range keys {
+		apiKeys[i] = apiKeyRecord{
+			ID:         k.ID,
+			Name:       k.Name,
+			Prefix:     k.Prefix,
+			Scopes:     k.Scopes,
+			CreatedAt:  k.CreatedAt,
+			ExpiresAt:  k.ExpiresAt,
+			LastUsedAt: k.LastUsedAt,
+			RevokedAt:  k.RevokedAt,
+		}
+	}
+
+	links, err := svc.store.Identities.ListByAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	identities := make([]identityRecord, len(links))
+	for i, l := range links {
+		identities[i] = identityRecord{Provider: l.Provider, Email: l.Email, CreatedAt: l.CreatedAt}
+	}
+
+	audit, err := svc.userAuditEvents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []userdata.Dataset{
+		{Name: "auth/account", Records: accounts},
+		{Name: "auth/sessions", Records: sessions},
+		{Name: "auth/api_keys", Records: apiKeys},
+		{Name: "auth/identities", Records: identities},
+		{Name: "auth/audit", Records: audit},
+	}, nil
+}
+
+// userAuditEvents returns the events the user performed or was subject to, oldest first.
+func (svc *AuthSvc) userAuditEvents(ctx context.Context, userID string) ([]auditRecord, error) {
+	byID := map[string]model.AuditEvent{}
+	for _, filter := range []repo.AuditFilter{
+		{SubjectID: userID, Limit: math.MaxInt},
+		{ActorID: userID, Limit: math.MaxInt},
+	} {
+		events, _, err := svc.store.Audit.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			byID[e.ID] = e
+		}
+	}
+
+	records := make([]auditRecord, 0, len(byID))
+	for _, e := range byID {
+		records = append(records, auditRecord{
+			ID:        e.ID,
+			Type:      string(e.Type),
+			ActorID:   e.ActorID,
+			SubjectID: e.SubjectID,
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			Outcome:   string(e.Outcome),
+			Details:   e.Details,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].CreatedAt.Before(records[j].CreatedAt) })
+	return records, nil
+}
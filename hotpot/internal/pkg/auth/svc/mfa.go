@@ -0,0 +1,346 @@
+package svc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"hotpot/internal/core/utils/ratelimit"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/skip2/go-qrcode"
+)
+
+var (
+	ErrInvalidCode       = errors.New("invalid verification code")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrNoPendingMFASetup = errors.New("no two-factor enrollment in progress")
+)
+
+const (
+	audienceMFA = "mfa"
+	// mfaTokenTTL is how long the user has to enter a code after the password step.
+	mfaTokenTTL = 5 * time.Minute
+	// mfaTokenAttempts is how many codes may be tried with one MFA token.
+	mfaTokenAttempts = 5
+
+	recoveryCodeCount = 10
+	recoveryCodeLen   = 10
+	qrCodeSize        = 256
+)
+
+// LoginResult is the outcome of the first login step. Exactly one of Tokens
+// and MFAToken is set; MFAToken must be exchanged with CompleteMFALogin.
+type LoginResult struct {
+	Tokens   *TokenPair
+	MFAToken string
+}
+
+// MFAEnrollment is what an authenticator app needs to register the account.
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+	QRCode []byte // PNG image of URI.
+}
+
+// StartSession finishes authentication for an account whose first factor has
+// been checked, asking for a second factor when two-factor auth is enabled.
+func (svc *AuthSvc) StartSession(ctx context.Context, account *model.Account, client ClientInfo) (*LoginResult, error) {
+	if account.TOTPEnabledAt != nil {
+		token, err := svc.issueActionToken(ctx, account, audienceMFA, mfaTokenTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{MFAToken: token}, nil
+	}
+
+	tokens, err := svc.IssueTokens(ctx, account, client)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// CompleteMFALogin checks the second factor, either an authenticator code or
+// a recovery code, and issues the token pair. The MFA token is used up by a
+// correct code, or by mfaTokenAttempts codes.
+func (svc *AuthSvc) CompleteMFALogin(ctx context.Context, mfaToken, code string, client ClientInfo) (*TokenPair, error) {
+	claims := &jwt.RegisteredClaims{}
+	if err := svc.parseJWT(mfaToken, claims, audienceMFA); err != nil {
+		return nil, err
+	}
+	record, err := svc.store.ActionTokens.FindByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if record.Purpose != audienceMFA || record.AccountID != claims.Subject || record.UsedAt != nil {
+		return nil, ErrInvalidToken
+	}
+
+	account, err := svc.store.Accounts.FindByID(ctx, record.AccountID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if account.TOTPEnabledAt == nil {
+		return nil, ErrMFANotEnabled
+	}
+
+	if err := svc.mfaAttempts.Allow(ctx, record.ID); err != nil {
+		var exceeded *ratelimit.ExceededError
+		if !errors.As(err, &exceeded) {
+			return nil, err
+		}
+		// Too many codes were tried with this token; the password must be
+		// entered again.
+		if err := svc.consumeMFAToken(ctx, record.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+	err = svc.verifyCode(ctx, account, model.AuditLogin, func() error {
+		return svc.checkSecondFactor(ctx, account, code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.consumeMFAToken(ctx, record.ID); err != nil {
+		return nil, err
+	}
+	return svc.IssueTokens(ctx, account, client)
+}
+
+// consumeMFAToken marks an MFA token used so it cannot be tried again.
+func (svc *AuthSvc) consumeMFAToken(ctx context.Context, id string) error {
+	if err := svc.store.ActionTokens.Consume(ctx, id, time.Now().UTC()); err != nil {
+		if errors.Is(err, repo.ErrConflict) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	return nil
+}
+
+// EnrollMFA generates a new authenticator secret for the account. It becomes
+// active only after ConfirmMFA.
+func (svc *AuthSvc) EnrollMFA(ctx context.Context, accountID string) (*MFAEnrollment, error) {
+	account, err := svc.store.Accounts.FindByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.TOTPEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	uri := totpURI(svc.cfg.TOTPIssuer, account.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	account.TOTPPendingSecret = secret
+	account.UpdatedAt = time.Now().UTC()
+	if err := svc.store.Accounts.Update(ctx, account); err != nil {
+		return nil, err
+	}
+	return &MFAEnrollment{Secret: secret, URI: uri, QRCode: png}, nil
+}
+
+// ConfirmMFA activates the pending secret once the user proves their app
+// generates matching codes, and returns a fresh set of recovery codes.
+func (svc *AuthSvc) ConfirmMFA(ctx context.Context, accountID, code string) ([]string, error) {
+	account, err := svc.store.Accounts.FindByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.TOTPEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if account.TOTPPendingSecret == "" {
+		return nil, ErrNoPendingMFASetup
+	}
+
+	now := time.Now().UTC()
+	var step int64
+	err = svc.verifyCode(ctx, account, model.AuditMFAEnable, func() error {
+		var ok bool
+		if step, ok = matchTOTP(account.TOTPPendingSecret, code, now, 0); !ok {
+			return ErrInvalidCode
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	account.TOTPSecret = account.TOTPPendingSecret
+	account.TOTPPendingSecret = ""
+	account.TOTPEnabledAt = &now
+	account.TOTPLastStep = step
+	account.RecoveryCodeHashes = hashes
+	account.UpdatedAt = now
+	if err := svc.store.Accounts.Update(ctx, account); err != nil {
+		return nil, err
+	}
+
+	svc.logger.Info("two-factor authentication enabled", "account_id", account.ID)
+	svc.RecordAudit(ctx, model.AuditMFAEnable, account.ID, model.AuditSuccess, nil)
+	return codes, nil
+}
+
+// DisableMFA turns two-factor auth off after checking a current code.
+func (svc *AuthSvc) DisableMFA(ctx context.Context, accountID, code string) error {
+	account, err := svc.store.Accounts.FindByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.TOTPEnabledAt == nil {
+		return ErrMFANotEnabled
+	}
+	err = svc.verifyCode(ctx, account, model.AuditMFADisable, func() error {
+		return svc.checkSecondFactor(ctx, account, code)
+	})
+	if err != nil {
+		return err
+	}
+
+	account.TOTPSecret = ""
+	account.TOTPEnabledAt = nil
+	account.TOTPLastStep = 0
+	account.RecoveryCodeHashes = nil
+	account.UpdatedAt = time.Now().UTC()
+	if err := svc.store.Accounts.Update(ctx, account); err != nil {
+		return err
+	}
+
+	svc.logger.Info("two-factor authentication disabled", "account_id", account.ID)
+	svc.RecordAudit(ctx, model.AuditMFADisable, account.ID, model.AuditSuccess, nil)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a current code.
+func (svc *AuthSvc) RegenerateRecoveryCodes(ctx context.Context, accountID, code string) ([]string, error) {
+	account, err := svc.store.Accounts.FindByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.TOTPEnabledAt == nil {
+		return nil, ErrMFANotEnabled
+	}
+	err = svc.verifyCode(ctx, account, model.AuditRecoveryCodes, func() error {
+		return svc.checkSecondFactor(ctx, account, code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload, since checkSecondFactor may have updated the account.
+	account, err = svc.store.Accounts.FindByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	account.RecoveryCodeHashes = hashes
+	account.UpdatedAt = time.Now().UTC()
+	if err := svc.store.Accounts.Update(ctx, account); err != nil {
+		return nil, err
+	}
+	svc.RecordAudit(ctx, model.AuditRecoveryCodes, account.ID, model.AuditSuccess, nil)
+	return codes, nil
+}
+
+// verifyCode runs check, which validates a code the user entered, under the
+// second-factor lockout of the account. Every form that takes a code shares
+// the lockout, so none of them can be used to guess codes faster.
+func (svc *AuthSvc) verifyCode(ctx context.Context, account *model.Account, eventType model.AuditEventType, check func() error) error {
+	lockKey := "mfa:" + account.ID
+	if err := svc.checkLockout(ctx, lockKey); err != nil {
+		svc.RecordAudit(ctx, eventType, account.ID, model.AuditFailure, map[string]string{"reason": "locked"})
+		return err
+	}
+	if err := check(); err != nil {
+		if errors.Is(err, ErrInvalidCode) {
+			svc.RecordAudit(ctx, eventType, account.ID, model.AuditFailure,
+				map[string]string{"reason": "invalid_second_factor"})
+			if lockErr := svc.recordFailure(ctx, lockKey); lockErr != nil {
+				return lockErr
+			}
+		}
+		return err
+	}
+	svc.clearFailures(ctx, lockKey)
+	return nil
+}
+
+// checkSecondFactor accepts either an authenticator code or an unused
+// recovery code, and records it so it cannot be used again.
+func (svc *AuthSvc) checkSecondFactor(ctx context.Context, account *model.Account, code string) error {
+	code = strings.ReplaceAll(strings.TrimSpace(code), "-", "")
+	now := time.Now().UTC()
+
+	if step, ok := matchTOTP(account.TOTPSecret, code, now, account.TOTPLastStep); ok {
+		account.TOTPLastStep = step
+		account.UpdatedAt = now
+		return svc.store.Accounts.Update(ctx, account)
+	}
+
+	hash := hashToken(strings.ToUpper(code))
+	for i, h := range account.RecoveryCodeHashes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			remaining := make([]string, 0, len(account.RecoveryCodeHashes)-1)
+			remaining = append(remaining, account.RecoveryCodeHashes[:i]...)
+			remaining = append(remaining, account.RecoveryCodeHashes[i+1:]...)
+			account.RecoveryCodeHashes = remaining
+			account.UpdatedAt = now
+
+			svc.logger.Info("recovery code used", "account_id", account.ID, "remaining", len(remaining))
+			return svc.store.Accounts.Update(ctx, account)
+		}
+	}
+	return ErrInvalidCode
+}
+
+// newRecoveryCodes returns recovery codes formatted for display as XXXXX-XXXXX,
+// together with the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	buf := make([]byte, recoveryCodeLen)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		for j, b := range buf {
+			buf[j] = alphabet[int(b)%len(alphabet)]
+		}
+		raw := string(buf)
+		codes[i] = raw[:recoveryCodeLen/2] + "-" + raw[recoveryCodeLen/2:]
+		hashes[i] = hashToken(raw)
+	}
+	return codes, hashes, nil
+}
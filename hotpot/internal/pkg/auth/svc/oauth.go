@@ -0,0 +1,176 @@
+package svc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown oauth provider")
+	ErrInvalidState    = errors.New("invalid or expired oauth state")
+	ErrOAuthFailed     = errors.New("oauth sign-in failed")
+)
+
+// oauthStateTTL bounds how long a user may take on the provider's consent screen.
+const oauthStateTTL = 10 * time.Minute
+
+// OAuthProvider is an external identity provider that supports the
+// authorization-code flow with PKCE.
+type OAuthProvider interface {
+	// Name is the identifier used in /auth/oauth/:provider routes.
+	Name() string
+	// AuthCodeURL builds the URL the user is sent to in order to sign in.
+	AuthCodeURL(state, codeChallenge, nonce string) string
+	// Exchange trades the authorization code for the identity of the signed-in user.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
+
+// ExternalIdentity is the user as reported by an OAuthProvider.
+type ExternalIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// StartOAuth begins an authorization-code flow and returns the provider URL
+// together with the state the callback must echo back.
+func (svc *AuthSvc) StartOAuth(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := svc.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state, err := newOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := newOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := newOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = svc.store.OAuthStates.Create(ctx, &model.OAuthState{
+		State:        state,
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return provider.AuthCodeURL(state, base64.RawURLEncoding.EncodeToString(challenge[:]), nonce), state, nil
+}
+
+// CompleteOAuth finishes the flow started by StartOAuth and returns the local
+// account linked to the external identity, creating or linking one if needed.
+func (svc *AuthSvc) CompleteOAuth(ctx context.Context, providerName, state, code string) (*model.Account, error) {
+	provider, ok := svc.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	pending, err := svc.store.OAuthStates.Take(ctx, state)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidState
+		}
+		return nil, err
+	}
+	if pending.Provider != providerName || time.Now().After(pending.ExpiresAt) {
+		return nil, ErrInvalidState
+	}
+
+	ext, err := provider.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		svc.logger.Warn("oauth exchange failed", "provider", providerName, "error", err)
+		return nil, ErrOAuthFailed
+	}
+
+	return svc.linkIdentity(ctx, providerName, ext)
+}
+
+// linkIdentity resolves an external identity to a local account. Unknown
+// identities are attached to the account with the same verified email,
+// or to a new password-less account.
+func (svc *AuthSvc) linkIdentity(ctx context.Context, providerName string, ext *ExternalIdentity) (*model.Account, error) {
+	identity, err := svc.store.Identities.FindBySubject(ctx, providerName, ext.Subject)
+	if err == nil {
+		account, err := svc.store.Accounts.FindByID(ctx, identity.AccountID)
+		if errors.Is(err, repo.ErrNotFound) {
+			// The account was erased; drop its identities so that the next
+			// sign-in starts over.
+			svc.logger.Warn("external identity of an erased account", "account_id", identity.AccountID, "provider", providerName)
+			if err := svc.store.Identities.DeleteByAccount(ctx, identity.AccountID); err != nil {
+				return nil, err
+			}
+			return nil, ErrOAuthFailed
+		}
+		return account, err
+	}
+	if !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	if ext.Email == "" || !ext.EmailVerified {
+		return nil, ErrOAuthFailed
+	}
+	email := normalizeEmail(ext.Email)
+
+	now := time.Now().UTC()
+	account, err := svc.store.Accounts.FindByEmail(ctx, email)
+	switch {
+	case errors.Is(err, repo.ErrNotFound):
+		account = &model.Account{
+			ID:              uuid.NewString(),
+			Email:           email,
+			Roles:           []model.Role{model.RoleUser},
+			EmailVerifiedAt: &now,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		svc.grantConfiguredRoles(account)
+		err = svc.store.Accounts.Create(ctx, account)
+	case err == nil && account.EmailVerifiedAt == nil:
+		// Whoever registered the unverified account never proved they own the
+		// address, so their password and sessions must not survive the link.
+		account.EmailVerifiedAt = &now
+		account.PasswordHash = nil
+		account.UpdatedAt = now
+		svc.grantConfiguredRoles(account)
+		if err = svc.store.Accounts.Update(ctx, account); err == nil {
+			err = svc.LogoutAll(ctx, account.ID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.store.Identities.Create(ctx, &model.Identity{
+		ID:        uuid.NewString(),
+		AccountID: account.ID,
+		Provider:  providerName,
+		Subject:   ext.Subject,
+		Email:     email,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	svc.logger.Info("external identity linked", "account_id", account.ID, "provider", providerName)
+	return account, nil
+}
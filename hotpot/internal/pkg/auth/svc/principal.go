@@ -0,0 +1,43 @@
+package svc
+
+import (
+	"context"
+	"hotpot/internal/pkg/auth/model"
+	"slices"
+)
+
+// Principal is the authenticated caller of a request, identified either by
+// an access token or by an API key.
+type Principal struct {
+	UserID        string
+	SessionID     string // Set for access tokens.
+	APIKeyID      string // Set for API keys.
+	EmailVerified bool
+	Roles         []model.Role
+	// Scopes restricts API keys; it is nil for access tokens, which are unrestricted.
+	Scopes []model.Scope
+}
+
+// IsAPIKey reports whether the caller authenticated with an API key.
+func (p *Principal) IsAPIKey() bool {
+	return p.APIKeyID != ""
+}
+
+// HasScope reports whether the caller may act within the scope.
+func (p *Principal) HasScope(scope model.Scope) bool {
+	return !p.IsAPIKey() || slices.Contains(p.Scopes, scope)
+}
+
+// AuthenticateToken verifies an access token and returns its caller.
+func (svc *AuthSvc) AuthenticateToken(ctx context.Context, token string) (*Principal, error) {
+	claims, err := svc.VerifyAccessToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{
+		UserID:        claims.Subject,
+		SessionID:     claims.SessionID,
+		EmailVerified: claims.EmailVerified,
+		Roles:         claims.Roles,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRole = errors.New("unknown role")
+
+// SetRoles replaces the roles of an account. Taking a role away revokes the
+// account's sessions, so the old roles do not live on in issued tokens.
+func (svc *AuthSvc) SetRoles(ctx context.Context, accountID string, roles []model.Role) (*model.Account, error) {
+	for _, r := range roles {
+		if !model.ValidRole(r) {
+			return nil, ErrInvalidRole
+		}
+	}
+	slices.Sort(roles)
+	roles = slices.Compact(roles)
+
+	account, err := svc.store.Accounts.FindByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	previous := account.Roles
+	revoked := slices.ContainsFunc(account.Roles, func(r model.Role) bool {
+		return !slices.Contains(roles, r)
+	})
+
+	account.Roles = roles
+	account.UpdatedAt = time.Now().UTC()
+	if err := svc.store.Accounts.Update(ctx, account); err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		if err := svc.LogoutAll(ctx, account.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	svc.logger.Info("account roles changed", "account_id", account.ID, "roles", roles)
+	svc.RecordAudit(ctx, model.AuditRoleChange, account.ID, model.AuditSuccess,
+		map[string]string{"from": joinRoles(previous), "to": joinRoles(roles)})
+	return account, nil
+}
+
+func joinRoles(roles []model.Role) string {
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = string(r)
+	}
+	return strings.Join(names, ",")
+}
+
+// grantConfiguredRoles makes the account an admin if its email is listed in
+// the configuration, which is how the first admin is bootstrapped. It must
+// only be called once the account's email is verified, since anyone may sign
+// up with a listed address.
+func (svc *AuthSvc) grantConfiguredRoles(account *model.Account) {
+	if account.EmailVerifiedAt == nil || !slices.Contains(svc.cfg.AdminEmails, account.Email) ||
+		slices.Contains(account.Roles, model.RoleAdmin) {
+		return
+	}
+	account.Roles = append(slices.Clone(account.Roles), model.RoleAdmin)
+	slices.Sort(account.Roles)
+	svc.logger.Info("configured admin role granted", "account_id", account.ID)
+}
@@ -0,0 +1,95 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"strings"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// maxDeviceNameLen caps client supplied device names, in characters.
+const maxDeviceNameLen = 100
+
+// ClientInfo describes the device a login or refresh comes from.
+type ClientInfo struct {
+	// DeviceName is chosen by the client app, e.g. "Anna's iPhone".
+	// When empty it is derived from UserAgent.
+	DeviceName string
+	IP         string
+	UserAgent  string
+}
+
+// ListSessions returns the account's active sessions, most recently seen first.
+func (svc *AuthSvc) ListSessions(ctx context.Context, accountID string) ([]model.Session, error) {
+	return svc.store.Sessions.ListActiveByAccount(ctx, accountID)
+}
+
+// RevokeSession signs one of the account's devices out.
+func (svc *AuthSvc) RevokeSession(ctx context.Context, accountID, sessionID string) error {
+	session, err := svc.store.Sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if session.AccountID != accountID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+	if err := svc.store.Sessions.Revoke(ctx, session.ID, time.Now().UTC()); err != nil {
+		return err
+	}
+	svc.RecordAudit(ctx, model.AuditTokenRevoke, accountID, model.AuditSuccess,
+		map[string]string{"session_id": session.ID, "device": session.DeviceName})
+	return nil
+}
+
+// deviceName returns the client supplied name, or a short description such as
+// "Firefox on Windows" guessed from the user agent.
+func deviceName(client ClientInfo) string {
+	if name := strings.TrimSpace(client.DeviceName); name != "" {
+		// Cut by runes, so that a multi-byte character is never split.
+		if runes := []rune(name); len(runes) > maxDeviceNameLen {
+			name = string(runes[:maxDeviceNameLen])
+		}
+		return name
+	}
+
+	ua := client.UserAgent
+	if ua == "" {
+		return "Unknown device"
+	}
+	browser := firstMatch(ua, [][2]string{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"}, {"Safari/", "Safari"},
+		{"okhttp", "Android app"}, {"CFNetwork", "iOS app"},
+	})
+	platform := firstMatch(ua, [][2]string{
+		{"Android", "Android"}, {"iPhone", "iOS"}, {"iPad", "iPadOS"},
+		{"Windows", "Windows"}, {"Mac OS X", "macOS"}, {"Linux", "Linux"},
+	})
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform + " device"
+	default:
+		return "Unknown device"
+	}
+}
+
+// firstMatch returns the label of the first needle found in s.
+func firstMatch(s string, needles [][2]string) string {
+	for _, n := range needles {
+		if strings.Contains(s, n[0]) {
+			return n[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,286 @@
+package svc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/repo"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid or expired token")
+	ErrTokenReused  = errors.New("refresh token reuse detected, session revoked")
+)
+
+// Audiences keep tokens signed with the same keys from being used for the wrong purpose.
+const (
+	audienceAccess            = "access"
+	audiencePasswordReset     = "password_reset"
+	audienceEmailVerification = "email_verification"
+)
+
+// AccessClaims are the claims carried by an access token.
+type AccessClaims struct {
+	jwt.RegisteredClaims
+	SessionID     string       `json:"sid"`
+	EmailVerified bool         `json:"ev"`
+	Roles         []model.Role `json:"roles"`
+}
+
+// TokenPair is the result of a successful authentication.
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// IssueTokens starts a new session for the account on the client's device
+// and returns its first token pair.
+func (svc *AuthSvc) IssueTokens(ctx context.Context, account *model.Account, client ClientInfo) (*TokenPair, error) {
+	now := time.Now().UTC()
+	session := &model.Session{
+		ID:         uuid.NewString(),
+		AccountID:  account.ID,
+		DeviceName: deviceName(client),
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	if err := svc.store.Sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	svc.RecordAudit(ctx, model.AuditLogin, account.ID, model.AuditSuccess,
+		map[string]string{"session_id": session.ID, "device": session.DeviceName})
+	return svc.issueTokens(ctx, account, session)
+}
+
+// Refresh exchanges a refresh token for a new token pair in the same session.
+// Presenting a token that was already exchanged revokes the whole session,
+// since either the client or an attacker is replaying a stolen token.
+func (svc *AuthSvc) Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*TokenPair, error) {
+	token, session, err := svc.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if token.RotatedAt != nil {
+		return nil, svc.revokeReusedSession(ctx, session, now)
+	}
+	if now.After(token.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	if err := svc.store.RefreshTokens.MarkRotated(ctx, token.ID, now); err != nil {
+		if errors.Is(err, repo.ErrConflict) {
+			return nil, svc.revokeReusedSession(ctx, session, now)
+		}
+		return nil, err
+	}
+
+	account, err := svc.store.Accounts.FindByID(ctx, token.AccountID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if err := svc.store.Sessions.Touch(ctx, session.ID, client.IP, client.UserAgent, now); err != nil {
+		return nil, err
+	}
+	return svc.issueTokens(ctx, account, session)
+}
+
+// Logout revokes the session the refresh token belongs to.
+func (svc *AuthSvc) Logout(ctx context.Context, refreshToken string) error {
+	_, session, err := svc.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	if err := svc.store.Sessions.Revoke(ctx, session.ID, time.Now().UTC()); err != nil {
+		return err
+	}
+	svc.RecordAudit(ctx, model.AuditTokenRevoke, session.AccountID, model.AuditSuccess,
+		map[string]string{"session_id": session.ID, "reason": "logout"})
+	return nil
+}
+
+// LogoutAll revokes every session of the account.
+func (svc *AuthSvc) LogoutAll(ctx context.Context, accountID string) error {
+	if err := svc.store.Sessions.RevokeByAccount(ctx, accountID, time.Now().UTC()); err != nil {
+		return err
+	}
+	svc.RecordAudit(ctx, model.AuditTokenRevoke, accountID, model.AuditSuccess, map[string]string{"session_id": "*"})
+	return nil
+}
+
+// VerifyAccessToken checks the signature, issuer and expiry of an access token
+// and makes sure its session has not been revoked since it was issued.
+func (svc *AuthSvc) VerifyAccessToken(ctx context.Context, token string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+	if err := svc.parseJWT(token, claims, audienceAccess); err != nil {
+		return nil, err
+	}
+
+	session, err := svc.store.Sessions.FindByID(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (svc *AuthSvc) issueTokens(ctx context.Context, account *model.Account, session *model.Session) (*TokenPair, error) {
+	now := time.Now().UTC()
+
+	access, accessExp, err := svc.signAccessToken(account, session, now)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	record := &model.RefreshToken{
+		ID:        uuid.NewString(),
+		SessionID: session.ID,
+		AccountID: account.ID,
+		TokenHash: hashToken(refresh),
+		CreatedAt: now,
+		ExpiresAt: now.Add(svc.cfg.RefreshTokenTTL),
+	}
+	if err := svc.store.RefreshTokens.Create(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  accessExp,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+// findRefreshToken looks up a refresh token and its session, rejecting
+// unknown tokens and tokens of revoked sessions.
+func (svc *AuthSvc) findRefreshToken(ctx context.Context, refreshToken string) (*model.RefreshToken, *model.Session, error) {
+	token, err := svc.store.RefreshTokens.FindByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, nil, ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+
+	session, err := svc.store.Sessions.FindByID(ctx, token.SessionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, nil, ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	return token, session, nil
+}
+
+func (svc *AuthSvc) revokeReusedSession(ctx context.Context, session *model.Session, now time.Time) error {
+	svc.logger.Warn("refresh token reuse detected", "account_id", session.AccountID, "session_id", session.ID)
+	if err := svc.store.Sessions.Revoke(ctx, session.ID, now); err != nil {
+		return err
+	}
+	svc.RecordAudit(ctx, model.AuditTokenReuse, session.AccountID, model.AuditFailure,
+		map[string]string{"session_id": session.ID})
+	return ErrTokenReused
+}
+
+func (svc *AuthSvc) signAccessToken(account *model.Account, session *model.Session, now time.Time) (string, time.Time, error) {
+	exp := now.Add(svc.cfg.AccessTokenTTL)
+	claims := AccessClaims{
+		RegisteredClaims: svc.registeredClaims(account.ID, audienceAccess, now, exp),
+		SessionID:        session.ID,
+		EmailVerified:    account.EmailVerifiedAt != nil,
+		Roles:            account.Roles,
+	}
+
+	signed, err := svc.signJWT(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, exp, nil
+}
+
+func (svc *AuthSvc) registeredClaims(subject, audience string, now, exp time.Time) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Issuer:    svc.cfg.JWTIssuer,
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(exp),
+	}
+}
+
+// signJWT signs the claims with the active signing key.
+func (svc *AuthSvc) signJWT(claims jwt.Claims) (string, error) {
+	key := svc.cfg.JWTSigningKeys[0]
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Secret)
+}
+
+// parseJWT verifies a token issued by signJWT for the given audience.
+func (svc *AuthSvc) parseJWT(token string, claims jwt.Claims, audience string) error {
+	_, err := jwt.ParseWithClaims(token, claims, svc.lookupKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(svc.cfg.JWTIssuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// lookupKey picks the verification key named by the token's kid header.
+func (svc *AuthSvc) lookupKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, key := range svc.cfg.JWTSigningKeys {
+		if key.ID == kid {
+			return key.Secret, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// newOpaqueToken returns 32 random bytes encoded as URL-safe base64.
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
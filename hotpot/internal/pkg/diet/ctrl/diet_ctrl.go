@@ -1,12 +1,20 @@
 package ctrl
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"hotpot/internal/core/utils/servers/http"
+	authmodel "hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/diet/dto"
+	"hotpot/internal/pkg/diet/model"
 	"hotpot/internal/pkg/diet/svc"
 	"log/slog"
+	"time"
 )
 
+const dateLayout = "2006-01-02"
+
 type DietCtrl struct {
 	logger  *slog.Logger
 	dietSvc *svc.DietSvc
@@ -26,3 +34,234 @@ func (c *DietCtrl) Ping(ctx *fiber.Ctx) error {
 	}
 	return http.NewResponse(ctx, http.OK, res, 0, "")
 }
+
+func (c *DietCtrl) CreateDiet(ctx *fiber.Ctx) error {
+	var q dto.DietQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+	var req dto.CreateDietReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	in := svc.DietInput{
+		Name:         req.Name,
+		Goal:         req.Goal,
+		DailyKcal:    req.DailyKcal,
+		Macros:       toMacroTargets(req.Macros),
+		WeeklyRateKg: req.WeeklyRateKg,
+		Adaptive:     req.Adaptive == nil || *req.Adaptive,
+		Activate:     req.Active == nil || *req.Active,
+	}
+	// Dates are validated by the DTO.
+	if req.StartDate != "" {
+		in.StartDate, _ = time.Parse(dateLayout, req.StartDate)
+	}
+	if req.EndDate != nil {
+		end, _ := time.Parse(dateLayout, *req.EndDate)
+		in.EndDate = &end
+	}
+
+	diet, err := c.dietSvc.CreateDiet(mw.RequestContext(ctx), caller(ctx), owner(ctx, q), in)
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Created, toDietRes(diet), 0, "")
+}
+
+func (c *DietCtrl) ListDiets(ctx *fiber.Ctx) error {
+	var q dto.DietQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	diets, err := c.dietSvc.Diets(mw.RequestContext(ctx), caller(ctx), owner(ctx, q))
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	res := make([]dto.DietRes, len(diets))
+	for i := range diets {
+		res[i] = toDietRes(&diets[i])
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func (c *DietCtrl) ActiveDiet(ctx *fiber.Ctx) error {
+	var q dto.DietQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	diet, err := c.dietSvc.ActiveDiet(mw.RequestContext(ctx), caller(ctx), owner(ctx, q))
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toDietRes(diet), 0, "")
+}
+
+func (c *DietCtrl) GetDiet(ctx *fiber.Ctx) error {
+	diet, err := c.dietSvc.Diet(mw.RequestContext(ctx), caller(ctx), ctx.Params("id"))
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toDietRes(diet), 0, "")
+}
+
+func (c *DietCtrl) UpdateDiet(ctx *fiber.Ctx) error {
+	var req dto.UpdateDietReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	update := svc.DietUpdate{
+		Name:         req.Name,
+		Goal:         req.Goal,
+		DailyKcal:    req.DailyKcal,
+		WeeklyRateKg: req.WeeklyRateKg,
+		Adaptive:     req.Adaptive,
+	}
+	if req.StartDate != nil {
+		start, _ := time.Parse(dateLayout, *req.StartDate)
+		update.StartDate = &start
+	}
+	if req.EndDate != nil {
+		// An empty end date parses to the zero time, which removes it.
+		end, _ := time.Parse(dateLayout, *req.EndDate)
+		update.EndDate = &end
+	}
+	if req.Macros != nil {
+		m := toMacroTargets(*req.Macros)
+		update.Macros = &m
+	}
+
+	diet, err := c.dietSvc.UpdateDiet(mw.RequestContext(ctx), caller(ctx), ctx.Params("id"), update)
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toDietRes(diet), 0, "")
+}
+
+func (c *DietCtrl) ActivateDiet(ctx *fiber.Ctx) error {
+	diet, err := c.dietSvc.ActivateDiet(mw.RequestContext(ctx), caller(ctx), ctx.Params("id"))
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toDietRes(diet), 0, "")
+}
+
+func (c *DietCtrl) ListAdjustments(ctx *fiber.Ctx) error {
+	adjustments, err := c.dietSvc.Adjustments(mw.RequestContext(ctx), caller(ctx), ctx.Params("id"))
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+
+	res := make([]dto.AdjustmentRes, len(adjustments))
+	for i, a := range adjustments {
+		res[i] = dto.AdjustmentRes{
+			ID:                a.ID,
+			WindowStart:       a.WindowStart.Format(dateLayout),
+			WindowEnd:         a.WindowEnd.Format(dateLayout),
+			LoggedDays:        a.LoggedDays,
+			AvgIntakeKcal:     a.AvgIntakeKcal,
+			WeightChangeKg:    a.WeightChangeKg,
+			EstimatedTDEEKcal: a.EstimatedTDEEKcal,
+			PreviousKcal:      a.PreviousKcal,
+			DailyKcal:         a.DailyKcal,
+			Macros:            toMacroTargetsRes(a.Macros, a.DailyKcal),
+			Explanation:       a.Explanation,
+			CreatedAt:         a.CreatedAt,
+		}
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func (c *DietCtrl) DeleteDiet(ctx *fiber.Ctx) error {
+	if err := c.dietSvc.DeleteDiet(mw.RequestContext(ctx), caller(ctx), ctx.Params("id")); err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+// dietError maps diet errors to responses.
+func (c *DietCtrl) dietError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrDietNotFound), errors.Is(err, svc.ErrNoActiveDiet), errors.Is(err, svc.ErrTemplateNotFound):
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	case errors.Is(err, svc.ErrInvalidDates), errors.Is(err, svc.ErrMacrosMismatch), errors.Is(err, svc.ErrInvalidRule):
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	case errors.Is(err, svc.ErrAccessDenied):
+		return http.NewResponse(ctx, http.Forbidden, nil, http.CodeForbidden, err.Error())
+	default:
+		c.logger.Error("diet request failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
+
+// caller returns the authenticated user. Dietitians may work on the diets
+// of clients who accepted them.
+func caller(ctx *fiber.Ctx) svc.Caller {
+	return svc.Caller{
+		ID:    mw.UserID(ctx),
+		Coach: mw.HasPermission(ctx, authmodel.PermManageClients),
+	}
+}
+
+// owner returns the user whose diets are requested.
+func owner(ctx *fiber.Ctx, q dto.DietQuery) string {
+	if q.UserID != "" {
+		return q.UserID
+	}
+	return mw.UserID(ctx)
+}
+
+func toMacroTargets(req dto.MacroTargetsReq) model.MacroTargets {
+	return model.MacroTargets{
+		Unit:    req.Unit,
+		Protein: req.Protein,
+		Carbs:   req.Carbs,
+		Fat:     req.Fat,
+	}
+}
+
+func toMacroTargetsRes(m model.MacroTargets, dailyKcal float64) dto.MacroTargetsRes {
+	d := model.Diet{DailyKcal: dailyKcal, Macros: m}
+	protein, carbs, fat := d.Grams()
+	return dto.MacroTargetsRes{
+		Unit:     m.Unit,
+		Protein:  m.Protein,
+		Carbs:    m.Carbs,
+		Fat:      m.Fat,
+		ProteinG: protein,
+		CarbsG:   carbs,
+		FatG:     fat,
+	}
+}
+
+func toDietRes(d *model.Diet) dto.DietRes {
+	res := dto.DietRes{
+		ID:             d.ID,
+		UserID:         d.UserID,
+		Name:           d.Name,
+		Goal:           d.Goal,
+		StartDate:      d.StartDate.Format(dateLayout),
+		DailyKcal:      d.DailyKcal,
+		Macros:         toMacroTargetsRes(d.Macros, d.DailyKcal),
+		WeeklyRateKg:   d.WeeklyRateKg,
+		Adaptive:       d.Adaptive,
+		RecalibratedAt: d.RecalibratedAt,
+		Rules:          toRulesRes(d.Rules),
+		Active:         d.Active,
+		CreatedBy:      d.CreatedBy,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+	if d.EndDate != nil {
+		end := d.EndDate.Format(dateLayout)
+		res.EndDate = &end
+	}
+	if d.TemplateID != "" {
+		res.TemplateID = &d.TemplateID
+	}
+	return res
+}
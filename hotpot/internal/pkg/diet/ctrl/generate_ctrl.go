@@ -0,0 +1,64 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/diet/dto"
+	"hotpot/internal/pkg/diet/svc"
+	usersvc "hotpot/internal/pkg/user/svc"
+	"time"
+)
+
+func (c *DietCtrl) GenerateDiet(ctx *fiber.Ctx) error {
+	var q dto.DietQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+	var req dto.GenerateDietReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	in := svc.GenerateInput{
+		Name:         req.Name,
+		GoalWeightKg: req.GoalWeightKg,
+		WeeklyRateKg: req.WeeklyRateKg,
+		Formula:      usersvc.Formula(req.Formula),
+	}
+	if req.StartDate != "" {
+		in.StartDate, _ = time.Parse(dateLayout, req.StartDate)
+	}
+
+	p, err := c.dietSvc.GenerateDiet(mw.RequestContext(ctx), caller(ctx), owner(ctx, q), in)
+	if err != nil {
+		var incomplete *usersvc.IncompleteProfileError
+		switch {
+		case errors.As(err, &incomplete),
+			errors.Is(err, usersvc.ErrFormulaUnavailable),
+			errors.Is(err, svc.ErrGoalUnreachable):
+			return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+		case errors.Is(err, usersvc.ErrUserNotFound):
+			return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+		default:
+			return c.dietError(ctx, err)
+		}
+	}
+
+	d := toDietRes(&p.Diet)
+	res := dto.GeneratedDietRes{
+		Name:         d.Name,
+		Goal:         d.Goal,
+		StartDate:    d.StartDate,
+		EndDate:      d.EndDate,
+		DailyKcal:    d.DailyKcal,
+		Macros:       d.Macros,
+		TDEEKcal:     p.TDEEKcal,
+		WeightKg:     p.WeightKg,
+		GoalWeightKg: p.GoalWeightKg,
+		WeeklyRateKg: p.WeeklyRateKg,
+		Adjustments:  p.Adjustments,
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
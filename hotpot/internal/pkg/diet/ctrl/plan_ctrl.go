@@ -0,0 +1,93 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/diet/dto"
+	"hotpot/internal/pkg/diet/model"
+	"hotpot/internal/pkg/diet/svc"
+	"time"
+)
+
+func (c *DietCtrl) PlanMeals(ctx *fiber.Ctx) error {
+	var req dto.PlanMealsReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	in := svc.PlanInput{
+		TolerancePct: req.TolerancePct,
+		MaxDailyCost: req.MaxDailyCost,
+		MaxRepeats:   req.MaxRepeats,
+	}
+	if req.StartDate != "" {
+		in.StartDate, _ = time.Parse(dateLayout, req.StartDate)
+	}
+
+	plan, err := c.dietSvc.PlanMeals(mw.RequestContext(ctx), caller(ctx), ctx.Params("id"), in)
+	if err != nil {
+		return c.planError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Created, toMealPlanRes(plan), 0, "")
+}
+
+func (c *DietCtrl) GetMealPlan(ctx *fiber.Ctx) error {
+	plan, err := c.dietSvc.MealPlan(mw.RequestContext(ctx), caller(ctx), ctx.Params("id"))
+	if err != nil {
+		return c.planError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toMealPlanRes(plan), 0, "")
+}
+
+// planError maps meal planning errors to responses.
+func (c *DietCtrl) planError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrPlanNotFound):
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	case errors.Is(err, svc.ErrNotEnoughFoods):
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	default:
+		return c.dietError(ctx, err)
+	}
+}
+
+func toMealPlanRes(plan *model.MealPlan) dto.MealPlanRes {
+	res := dto.MealPlanRes{
+		ID:           plan.ID,
+		DietID:       plan.DietID,
+		StartDate:    plan.StartDate.Format(dateLayout),
+		TolerancePct: plan.TolerancePct,
+		MaxDailyCost: plan.MaxDailyCost,
+		Days:         make([]dto.PlanDayRes, len(plan.Days)),
+		CreatedAt:    plan.CreatedAt,
+	}
+	for i, d := range plan.Days {
+		day := dto.PlanDayRes{
+			Date:     d.Date.Format(dateLayout),
+			Meals:    make([]dto.PlannedMealRes, len(d.Meals)),
+			Kcal:     d.Kcal,
+			ProteinG: d.ProteinG,
+			CarbsG:   d.CarbsG,
+			FatG:     d.FatG,
+			Cost:     d.Cost,
+			OnTarget: d.OnTarget,
+		}
+		for j, m := range d.Meals {
+			day.Meals[j] = dto.PlannedMealRes{
+				Meal:     m.Meal,
+				FoodID:   m.FoodID,
+				Name:     m.Name,
+				Servings: m.Servings,
+				Kcal:     m.Kcal,
+				ProteinG: m.ProteinG,
+				CarbsG:   m.CarbsG,
+				FatG:     m.FatG,
+				Cost:     m.Cost,
+			}
+		}
+		res.Days[i] = day
+	}
+	return res
+}
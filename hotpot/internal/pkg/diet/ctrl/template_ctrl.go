@@ -0,0 +1,192 @@
+package ctrl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/diet/dto"
+	"hotpot/internal/pkg/diet/model"
+	"hotpot/internal/pkg/diet/svc"
+	"time"
+)
+
+// violationDays is how many days of violations are listed by default.
+const violationDays = 7
+
+func (c *DietCtrl) ListTemplates(ctx *fiber.Ctx) error {
+	templates, err := c.dietSvc.Templates(ctx.Context())
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	res := make([]dto.TemplateRes, len(templates))
+	for i := range templates {
+		res[i] = toTemplateRes(&templates[i])
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func (c *DietCtrl) GetTemplate(ctx *fiber.Ctx) error {
+	template, err := c.dietSvc.Template(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toTemplateRes(template), 0, "")
+}
+
+func (c *DietCtrl) CreateTemplate(ctx *fiber.Ctx) error {
+	var req dto.TemplateReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	template, err := c.dietSvc.CreateTemplate(ctx.Context(), toTemplateInput(req))
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Created, toTemplateRes(template), 0, "")
+}
+
+func (c *DietCtrl) UpdateTemplate(ctx *fiber.Ctx) error {
+	var req dto.TemplateReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	template, err := c.dietSvc.UpdateTemplate(ctx.Context(), ctx.Params("id"), toTemplateInput(req))
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toTemplateRes(template), 0, "")
+}
+
+func (c *DietCtrl) DeleteTemplate(ctx *fiber.Ctx) error {
+	if err := c.dietSvc.DeleteTemplate(ctx.Context(), ctx.Params("id")); err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *DietCtrl) CreateDietFromTemplate(ctx *fiber.Ctx) error {
+	var q dto.DietQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+	var req dto.CreateDietFromTemplateReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	in := svc.TemplateDietInput{
+		Name:         req.Name,
+		Goal:         req.Goal,
+		DailyKcal:    req.DailyKcal,
+		WeeklyRateKg: req.WeeklyRateKg,
+		Adaptive:     req.Adaptive == nil || *req.Adaptive,
+		Activate:     req.Active == nil || *req.Active,
+	}
+	// Dates are validated by the DTO.
+	if req.StartDate != "" {
+		in.StartDate, _ = time.Parse(dateLayout, req.StartDate)
+	}
+	if req.EndDate != nil {
+		end, _ := time.Parse(dateLayout, *req.EndDate)
+		in.EndDate = &end
+	}
+
+	diet, err := c.dietSvc.CreateDietFromTemplate(mw.RequestContext(ctx), caller(ctx), owner(ctx, q), ctx.Params("id"), in)
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Created, toDietRes(diet), 0, "")
+}
+
+func (c *DietCtrl) ListViolations(ctx *fiber.Ctx) error {
+	var q dto.ViolationQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	to := time.Now().UTC()
+	if q.To != "" {
+		to, _ = time.Parse(dateLayout, q.To)
+	}
+	from := to.AddDate(0, 0, 1-violationDays)
+	if q.From != "" {
+		from, _ = time.Parse(dateLayout, q.From)
+	}
+
+	violations, err := c.dietSvc.RuleViolations(mw.RequestContext(ctx), caller(ctx), ctx.Params("id"), from, to)
+	if err != nil {
+		return c.dietError(ctx, err)
+	}
+	res := make([]dto.ViolationRes, len(violations))
+	for i, v := range violations {
+		res[i] = dto.ViolationRes{
+			Date:     v.Date.Format(dateLayout),
+			Meal:     v.Meal,
+			Rule:     toRuleRes(v.Rule),
+			Value:    v.Value,
+			Message:  v.Message(),
+			EntryIDs: v.EntryIDs,
+		}
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func toTemplateInput(req dto.TemplateReq) svc.TemplateInput {
+	in := svc.TemplateInput{
+		Name:        req.Name,
+		Summary:     req.Summary,
+		Description: req.Description,
+		Macros: model.MacroTargets{
+			Unit:    model.MacroPercent,
+			Protein: req.Macros.Protein,
+			Carbs:   req.Macros.Carbs,
+			Fat:     req.Macros.Fat,
+		},
+	}
+	for _, r := range req.Rules {
+		in.Rules = append(in.Rules, model.Rule{
+			Nutrient: r.Nutrient,
+			Scope:    r.Scope,
+			Min:      r.Min,
+			Max:      r.Max,
+			PctKcal:  r.PctKcal,
+		})
+	}
+	return in
+}
+
+func toTemplateRes(t *model.Template) dto.TemplateRes {
+	return dto.TemplateRes{
+		ID:          t.ID,
+		Name:        t.Name,
+		Summary:     t.Summary,
+		Description: t.Description,
+		Macros: dto.TemplateMacrosRes{
+			Protein: t.Macros.Protein,
+			Carbs:   t.Macros.Carbs,
+			Fat:     t.Macros.Fat,
+		},
+		Rules:     toRulesRes(t.Rules),
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func toRuleRes(r model.Rule) dto.RuleRes {
+	return dto.RuleRes{
+		Nutrient: r.Nutrient,
+		Scope:    r.Scope,
+		Min:      r.Min,
+		Max:      r.Max,
+		PctKcal:  r.PctKcal,
+	}
+}
+
+func toRulesRes(rules []model.Rule) []dto.RuleRes {
+	res := make([]dto.RuleRes, len(rules))
+	for i, r := range rules {
+		res[i] = toRuleRes(r)
+	}
+	return res
+}
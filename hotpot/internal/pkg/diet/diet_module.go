@@ -1,29 +1,48 @@
 package diet
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/cfg"
+	"hotpot/internal/core/utils/servers/scheduler"
+	"hotpot/internal/core/utils/userdata"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/mw"
 	"hotpot/internal/pkg/diet/ctrl"
+	"hotpot/internal/pkg/diet/repo"
 	"hotpot/internal/pkg/diet/svc"
 	"log/slog"
+	"time"
 )
 
 type Module struct {
 	Name    string
 	Version string
 
-	logger         *slog.Logger
-	DietController *ctrl.DietCtrl
+	logger           *slog.Logger
+	authMw           *mw.AuthMw
+	recalibrateEvery time.Duration
+	DietController   *ctrl.DietCtrl
+	Service          *svc.DietSvc
 }
 
-func New(logger *slog.Logger) *Module {
+func New(
+	logger *slog.Logger,
+	config *cfg.Config,
+	authMw *mw.AuthMw,
+	users svc.UserService,
+	meals svc.MealService,
+) *Module {
+	dietSvc := svc.NewDietService(logger, config, repo.NewMemoryStore(), users, meals)
+
 	mod := &Module{
-		Name:    "diet-module",
-		Version: "v1",
-		logger:  logger,
-		DietController: ctrl.NewDietController(
-			logger,
-			svc.NewDietService(logger),
-		),
+		Name:             "diet-module",
+		Version:          "v1",
+		logger:           logger,
+		authMw:           authMw,
+		recalibrateEvery: config.DietRecalibrationInterval,
+		DietController:   ctrl.NewDietController(logger, dietSvc),
+		Service:          dietSvc,
 	}
 	return mod
 }
@@ -35,4 +54,46 @@ func (m *Module) InitHTTPRoutes(r fiber.Router) {
 
 	modGroup := root.Group("/diet")
 	modGroup.Get("/ping", m.DietController.Ping)
+
+	// Dietitians reach their clients' diets with ?user_id= on the collection routes.
+	modGroup.Post("/", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsWrite), m.DietController.CreateDiet)
+	modGroup.Get("/", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsRead), m.DietController.ListDiets)
+	modGroup.Post("/generate", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsRead), m.DietController.GenerateDiet)
+	modGroup.Get("/active", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsRead), m.DietController.ActiveDiet)
+
+	// The template library is shared by all users and curated by admins.
+	templateGroup := modGroup.Group("/templates", m.authMw.Authenticate)
+	templateGroup.Get("/", m.authMw.RequireScope(model.ScopeDietsRead), m.DietController.ListTemplates)
+	templateGroup.Get("/:id", m.authMw.RequireScope(model.ScopeDietsRead), m.DietController.GetTemplate)
+	templateGroup.Post("/:id/diets", m.authMw.RequireScope(model.ScopeDietsWrite), m.DietController.CreateDietFromTemplate)
+	templateGroup.Post("/", m.authMw.RequireSession, m.authMw.RequireVerifiedEmail, m.authMw.RequirePermission(model.PermManageCatalog), m.DietController.CreateTemplate)
+	templateGroup.Put("/:id", m.authMw.RequireSession, m.authMw.RequireVerifiedEmail, m.authMw.RequirePermission(model.PermManageCatalog), m.DietController.UpdateTemplate)
+	templateGroup.Delete("/:id", m.authMw.RequireSession, m.authMw.RequireVerifiedEmail, m.authMw.RequirePermission(model.PermManageCatalog), m.DietController.DeleteTemplate)
+
+	modGroup.Get("/:id", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsRead), m.DietController.GetDiet)
+	modGroup.Patch("/:id", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsWrite), m.DietController.UpdateDiet)
+	modGroup.Get("/:id/adjustments", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsRead), m.DietController.ListAdjustments)
+	modGroup.Get("/:id/violations", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsRead), m.DietController.ListViolations)
+	modGroup.Post("/:id/plan", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsWrite), m.DietController.PlanMeals)
+	modGroup.Get("/:id/plan", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsRead), m.DietController.GetMealPlan)
+	modGroup.Post("/:id/activate", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsWrite), m.DietController.ActivateDiet)
+	modGroup.Delete("/:id", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeDietsWrite), m.DietController.DeleteDiet)
+}
+
+// ExportUserData adds the user's diets and their recalibrations to data exports.
+func (m *Module) ExportUserData(ctx context.Context, userID string) ([]userdata.Dataset, error) {
+	return m.Service.ExportUserData(ctx, userID)
+}
+
+// EraseUserData removes the user's diet data when their account is deleted.
+func (m *Module) EraseUserData(ctx context.Context, userID string) error {
+	return m.Service.EraseUserData(ctx, userID)
+}
+
+func (m *Module) InitJobs(s *scheduler.Scheduler) {
+	s.Add(scheduler.Job{
+		Name:     "diet-recalibration",
+		Interval: m.recalibrateEvery,
+		Run:      m.Service.RecalibrateDiets,
+	})
 }
@@ -0,0 +1,257 @@
+package dto
+
+import (
+	"hotpot/internal/pkg/diet/model"
+	mealmodel "hotpot/internal/pkg/meal/model"
+	"time"
+)
+
+type MacroTargetsReq struct {
+	// Unit is "g" for grams per day or "pct" for shares of the daily energy.
+	Unit    model.MacroUnit `json:"unit" validate:"required,oneof=g pct"`
+	Protein float64         `json:"protein" validate:"gte=0,lte=1000"`
+	Carbs   float64         `json:"carbs" validate:"gte=0,lte=2000"`
+	Fat     float64         `json:"fat" validate:"gte=0,lte=1000"`
+}
+
+type CreateDietReq struct {
+	Name string     `json:"name" validate:"required,max=100"`
+	Goal model.Goal `json:"goal" validate:"required,oneof=lose maintain gain"`
+	// StartDate and EndDate are formatted as YYYY-MM-DD. The start date
+	// defaults to today.
+	StartDate string          `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   *string         `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	DailyKcal float64         `json:"daily_kcal" validate:"required,gte=800,lte=10000"`
+	Macros    MacroTargetsReq `json:"macros"`
+	// WeeklyRateKg is how many kilograms a week to lose or gain.
+	WeeklyRateKg float64 `json:"weekly_rate_kg" validate:"gte=0,lte=2"`
+	// Adaptive defaults to true, letting the daily energy follow the
+	// expenditure measured from logged meals and weight.
+	Adaptive *bool `json:"adaptive"`
+	// Active defaults to true, replacing the current active diet.
+	Active *bool `json:"active"`
+}
+
+// UpdateDietReq is a partial update; omitted fields keep their value.
+type UpdateDietReq struct {
+	Name      *string     `json:"name" validate:"omitempty,min=1,max=100"`
+	Goal      *model.Goal `json:"goal" validate:"omitempty,oneof=lose maintain gain"`
+	StartDate *string     `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	// EndDate may be set to an empty string to remove the end date.
+	EndDate      *string          `json:"end_date" validate:"omitempty,datetime=2006-01-02|eq="`
+	DailyKcal    *float64         `json:"daily_kcal" validate:"omitempty,gte=800,lte=10000"`
+	Macros       *MacroTargetsReq `json:"macros"`
+	WeeklyRateKg *float64         `json:"weekly_rate_kg" validate:"omitempty,gte=0,lte=2"`
+	Adaptive     *bool            `json:"adaptive"`
+}
+
+type DietQuery struct {
+	// UserID selects a client's diets. It defaults to the caller.
+	UserID string `query:"user_id" validate:"omitempty,uuid"`
+}
+
+type MacroTargetsRes struct {
+	Unit    model.MacroUnit `json:"unit"`
+	Protein float64         `json:"protein"`
+	Carbs   float64         `json:"carbs"`
+	Fat     float64         `json:"fat"`
+	// Grams are the targets in grams per day, whatever the unit.
+	ProteinG float64 `json:"protein_g"`
+	CarbsG   float64 `json:"carbs_g"`
+	FatG     float64 `json:"fat_g"`
+}
+
+type DietRes struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Name      string          `json:"name"`
+	Goal      model.Goal      `json:"goal"`
+	StartDate string          `json:"start_date"`
+	EndDate   *string         `json:"end_date"`
+	DailyKcal float64         `json:"daily_kcal"`
+	Macros    MacroTargetsRes `json:"macros"`
+	// WeeklyRateKg is the rate recalibration aims for; zero means the
+	// default of the goal.
+	WeeklyRateKg   float64    `json:"weekly_rate_kg"`
+	Adaptive       bool       `json:"adaptive"`
+	RecalibratedAt *time.Time `json:"recalibrated_at"`
+	// TemplateID is the template the diet was created from, if any, and
+	// Rules the limits its logged meals are checked against.
+	TemplateID *string   `json:"template_id"`
+	Rules      []RuleRes `json:"rules"`
+	Active     bool      `json:"active"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type GenerateDietReq struct {
+	Name         string  `json:"name" validate:"max=100"`
+	GoalWeightKg float64 `json:"goal_weight_kg" validate:"required,gt=20,lt=500"`
+	// WeeklyRateKg is how many kilograms to lose or gain per week.
+	WeeklyRateKg float64 `json:"weekly_rate_kg" validate:"gte=0,lte=2"`
+	// StartDate is formatted as YYYY-MM-DD and defaults to today.
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	Formula   string `json:"formula" validate:"omitempty,oneof=mifflin_st_jeor harris_benedict katch_mcardle"`
+}
+
+// GeneratedDietRes is a proposed diet that can be saved with POST /diet.
+type GeneratedDietRes struct {
+	Name      string     `json:"name"`
+	Goal      model.Goal `json:"goal"`
+	StartDate string     `json:"start_date"`
+	// EndDate is the estimated date the goal weight is reached.
+	EndDate      *string         `json:"end_date"`
+	DailyKcal    float64         `json:"daily_kcal"`
+	Macros       MacroTargetsRes `json:"macros"`
+	TDEEKcal     float64         `json:"tdee_kcal"`
+	WeightKg     float64         `json:"weight_kg"`
+	GoalWeightKg float64         `json:"goal_weight_kg"`
+	// WeeklyRateKg is the rate the targets allow, after safety limits.
+	WeeklyRateKg float64 `json:"weekly_rate_kg"`
+	// Adjustments lists the safety limits applied, e.g. "rate_capped" or "kcal_floor".
+	Adjustments []string `json:"adjustments"`
+}
+
+type AdjustmentRes struct {
+	ID                string          `json:"id"`
+	WindowStart       string          `json:"window_start"`
+	WindowEnd         string          `json:"window_end"`
+	LoggedDays        int             `json:"logged_days"`
+	AvgIntakeKcal     float64         `json:"avg_intake_kcal"`
+	WeightChangeKg    float64         `json:"weight_change_kg"`
+	EstimatedTDEEKcal float64         `json:"estimated_tdee_kcal"`
+	PreviousKcal      float64         `json:"previous_kcal"`
+	DailyKcal         float64         `json:"daily_kcal"`
+	Macros            MacroTargetsRes `json:"macros"`
+	Explanation       string          `json:"explanation"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+type PlanMealsReq struct {
+	// StartDate is formatted as YYYY-MM-DD and defaults to tomorrow.
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	// TolerancePct is how far each day may be off the targets; default 10.
+	TolerancePct float64 `json:"tolerance_pct" validate:"omitempty,gte=2,lte=30"`
+	// MaxDailyCost caps the cost of each day in the app's currency.
+	MaxDailyCost *float64 `json:"max_daily_cost" validate:"omitempty,gt=0"`
+	// MaxRepeats is how often the same food may appear in the week; default 2.
+	MaxRepeats int `json:"max_repeats" validate:"omitempty,gte=1,lte=14"`
+}
+
+type PlannedMealRes struct {
+	Meal     mealmodel.MealType `json:"meal"`
+	FoodID   string             `json:"food_id"`
+	Name     string             `json:"name"`
+	Servings float64            `json:"servings"`
+	Kcal     float64            `json:"kcal"`
+	ProteinG float64            `json:"protein_g"`
+	CarbsG   float64            `json:"carbs_g"`
+	FatG     float64            `json:"fat_g"`
+	Cost     float64            `json:"cost"`
+}
+
+type PlanDayRes struct {
+	Date     string           `json:"date"`
+	Meals    []PlannedMealRes `json:"meals"`
+	Kcal     float64          `json:"kcal"`
+	ProteinG float64          `json:"protein_g"`
+	CarbsG   float64          `json:"carbs_g"`
+	FatG     float64          `json:"fat_g"`
+	Cost     float64          `json:"cost"`
+	// OnTarget tells whether the day meets every target within the tolerance.
+	OnTarget bool `json:"on_target"`
+}
+
+type MealPlanRes struct {
+	ID           string       `json:"id"`
+	DietID       string       `json:"diet_id"`
+	StartDate    string       `json:"start_date"`
+	TolerancePct float64      `json:"tolerance_pct"`
+	MaxDailyCost *float64     `json:"max_daily_cost"`
+	Days         []PlanDayRes `json:"days"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+type RuleReq struct {
+	Nutrient model.Nutrient  `json:"nutrient" validate:"required,oneof=kcal protein_g carbs_g net_carbs_g fat_g saturated_fat_g fiber_g sugar_g sodium_mg"`
+	Scope    model.RuleScope `json:"scope" validate:"required,oneof=day meal"`
+	// Min and Max are amounts in the nutrient's unit, or percentages of the
+	// energy eaten when PctKcal is set. At least one is required.
+	Min     *float64 `json:"min" validate:"required_without=Max,omitempty,gte=0"`
+	Max     *float64 `json:"max" validate:"required_without=Min,omitempty,gte=0"`
+	PctKcal bool     `json:"pct_kcal"`
+}
+
+// TemplateMacrosReq is the macro split of a template in percent of the
+// daily energy.
+type TemplateMacrosReq struct {
+	Protein float64 `json:"protein" validate:"gte=0,lte=100"`
+	Carbs   float64 `json:"carbs" validate:"gte=0,lte=100"`
+	Fat     float64 `json:"fat" validate:"gte=0,lte=100"`
+}
+
+type TemplateReq struct {
+	Name        string            `json:"name" validate:"required,max=100"`
+	Summary     string            `json:"summary" validate:"max=300"`
+	Description string            `json:"description" validate:"max=5000"`
+	Macros      TemplateMacrosReq `json:"macros"`
+	Rules       []RuleReq         `json:"rules" validate:"max=20,dive"`
+}
+
+type CreateDietFromTemplateReq struct {
+	// Name defaults to the template's name and Goal to "maintain".
+	Name string     `json:"name" validate:"max=100"`
+	Goal model.Goal `json:"goal" validate:"omitempty,oneof=lose maintain gain"`
+	// StartDate and EndDate are formatted as YYYY-MM-DD. The start date
+	// defaults to today.
+	StartDate    string  `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate      *string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	DailyKcal    float64 `json:"daily_kcal" validate:"required,gte=800,lte=10000"`
+	WeeklyRateKg float64 `json:"weekly_rate_kg" validate:"gte=0,lte=2"`
+	// Adaptive and Active default to true, as for POST /diet.
+	Adaptive *bool `json:"adaptive"`
+	Active   *bool `json:"active"`
+}
+
+type ViolationQuery struct {
+	// From and To are dates formatted as YYYY-MM-DD; both are inclusive.
+	// They default to the last 7 days.
+	From string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To   string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type RuleRes struct {
+	Nutrient model.Nutrient  `json:"nutrient"`
+	Scope    model.RuleScope `json:"scope"`
+	Min      *float64        `json:"min"`
+	Max      *float64        `json:"max"`
+	PctKcal  bool            `json:"pct_kcal"`
+}
+
+type TemplateMacrosRes struct {
+	Protein float64 `json:"protein"`
+	Carbs   float64 `json:"carbs"`
+	Fat     float64 `json:"fat"`
+}
+
+type TemplateRes struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Summary     string            `json:"summary"`
+	Description string            `json:"description"`
+	Macros      TemplateMacrosRes `json:"macros"`
+	Rules       []RuleRes         `json:"rules"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+type ViolationRes struct {
+	Date string `json:"date"`
+	// Meal is empty for rules on whole days.
+	Meal mealmodel.MealType `json:"meal,omitempty"`
+	Rule RuleRes            `json:"rule"`
+	// Value is the amount eaten, or its percentage of the energy.
+	Value    float64  `json:"value"`
+	Message  string   `json:"message"`
+	EntryIDs []string `json:"entry_ids"`
+}
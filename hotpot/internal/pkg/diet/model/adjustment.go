@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Adjustment records a recalibration of a diet's daily energy, with the
+// data it was based on and an explanation for the user.
+type Adjustment struct {
+	ID     string
+	DietID string
+	UserID string
+	// WindowStart and WindowEnd are the first and last day the estimate
+	// is based on.
+	WindowStart time.Time
+	WindowEnd   time.Time
+	LoggedDays  int
+	// AvgIntakeKcal is the mean energy of the logged days.
+	AvgIntakeKcal float64
+	// WeightChangeKg is the change of the smoothed weight over the window.
+	WeightChangeKg float64
+	// EstimatedTDEEKcal is the daily expenditure the intake and weight
+	// change imply.
+	EstimatedTDEEKcal float64
+	PreviousKcal      float64
+	DailyKcal         float64
+	PreviousMacros    MacroTargets
+	Macros            MacroTargets
+	Explanation       string
+	CreatedAt         time.Time
+}
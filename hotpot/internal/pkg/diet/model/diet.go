@@ -0,0 +1,93 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// Goal is what the user wants to achieve with a diet.
+type Goal string
+
+const (
+	GoalLose     Goal = "lose"
+	GoalMaintain Goal = "maintain"
+	GoalGain     Goal = "gain"
+)
+
+// MacroUnit tells how macronutrient targets are expressed.
+type MacroUnit string
+
+const (
+	MacroGrams   MacroUnit = "g"   // Grams per day.
+	MacroPercent MacroUnit = "pct" // Percentage of the daily energy.
+)
+
+// Energy per gram of each macronutrient, in kcal.
+const (
+	KcalPerGramProtein = 4
+	KcalPerGramCarbs   = 4
+	KcalPerGramFat     = 9
+)
+
+// MacroTargets are the daily protein, carbohydrate and fat targets.
+type MacroTargets struct {
+	Unit    MacroUnit
+	Protein float64
+	Carbs   float64
+	Fat     float64
+}
+
+// Diet is a plan with daily energy and macronutrient targets that the
+// user's meals are measured against. A user has at most one active diet.
+type Diet struct {
+	ID     string
+	UserID string
+	Name   string
+	Goal   Goal
+	// StartDate and EndDate are calendar dates at midnight UTC. A diet
+	// without an end date runs until it is replaced.
+	StartDate time.Time
+	EndDate   *time.Time
+	DailyKcal float64
+	Macros    MacroTargets
+	// WeeklyRateKg is how many kilograms a week the user means to lose or
+	// gain. Recalibration aims for it; zero picks a default for the goal.
+	WeeklyRateKg float64
+	// Adaptive diets have their daily energy recalibrated from logged intake
+	// and the weight trend. RecalibratedAt is when that was last checked.
+	Adaptive       bool
+	RecalibratedAt *time.Time
+	// TemplateID is the template the diet was created from, if any. Its
+	// rules are copied, so later edits of the template leave the diet as
+	// it is.
+	TemplateID string
+	Rules      []Rule
+	Active     bool
+	// CreatedBy is the user who created the diet: its owner or their coach.
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Grams returns the macronutrient targets in grams per day.
+func (d *Diet) Grams() (protein, carbs, fat float64) {
+	m := d.Macros
+	if m.Unit != MacroPercent {
+		return m.Protein, m.Carbs, m.Fat
+	}
+	grams := func(pct, kcalPerGram float64) float64 {
+		return math.Round(d.DailyKcal*pct/100/kcalPerGram*10) / 10
+	}
+	return grams(m.Protein, KcalPerGramProtein), grams(m.Carbs, KcalPerGramCarbs), grams(m.Fat, KcalPerGramFat)
+}
+
+// MacroKcal returns the energy of the macronutrient targets in kcal.
+func (d *Diet) MacroKcal() float64 {
+	p, c, f := d.Grams()
+	return p*KcalPerGramProtein + c*KcalPerGramCarbs + f*KcalPerGramFat
+}
+
+// Covers reports whether the diet runs on the given day.
+func (d *Diet) Covers(day time.Time) bool {
+	return !day.Before(d.StartDate) && (d.EndDate == nil || !day.After(*d.EndDate))
+}
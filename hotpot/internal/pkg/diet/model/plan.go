@@ -0,0 +1,50 @@
+package model
+
+import (
+	mealmodel "hotpot/internal/pkg/meal/model"
+	"time"
+)
+
+// PlannedMeal is a catalog food in a meal plan. Nutrients and cost are for
+// the planned servings; they are copied so the plan survives catalog edits.
+type PlannedMeal struct {
+	Meal     mealmodel.MealType
+	FoodID   string
+	Name     string
+	Servings float64
+	Kcal     float64
+	ProteinG float64
+	CarbsG   float64
+	FatG     float64
+	Cost     float64
+}
+
+// PlanDay is one day of a meal plan with its totals.
+type PlanDay struct {
+	Date     time.Time
+	Meals    []PlannedMeal
+	Kcal     float64
+	ProteinG float64
+	CarbsG   float64
+	FatG     float64
+	Cost     float64
+	// OnTarget is set when energy and every macronutrient are within the
+	// plan's tolerance of the diet's targets, the cost within the cap and
+	// no food over the repeat limit.
+	OnTarget bool
+}
+
+// MealPlan is a week of meals chosen to meet a diet's targets. A diet keeps
+// only its latest plan.
+type MealPlan struct {
+	ID        string
+	DietID    string
+	UserID    string
+	StartDate time.Time
+	Days      []PlanDay
+	// TolerancePct is how far each day may be off the targets, in percent.
+	TolerancePct float64
+	// MaxDailyCost caps the cost of a day; nil means no cap.
+	MaxDailyCost *float64
+	CreatedAt    time.Time
+}
@@ -0,0 +1,245 @@
+package model
+
+import (
+	"fmt"
+	mealmodel "hotpot/internal/pkg/meal/model"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Nutrient is a quantity of food that diet rules limit.
+type Nutrient string
+
+const (
+	NutrientKcal         Nutrient = "kcal"
+	NutrientProtein      Nutrient = "protein_g"
+	NutrientCarbs        Nutrient = "carbs_g"
+	NutrientNetCarbs     Nutrient = "net_carbs_g" // Carbohydrates less fiber.
+	NutrientFat          Nutrient = "fat_g"
+	NutrientSaturatedFat Nutrient = "saturated_fat_g"
+	NutrientFiber        Nutrient = "fiber_g"
+	NutrientSugar        Nutrient = "sugar_g"
+	NutrientSodium       Nutrient = "sodium_mg"
+)
+
+var nutrientInfo = map[Nutrient]struct {
+	label string
+	unit  string
+	// kcalPerUnit is the energy of a gram, for nutrients that rules may
+	// bound as a share of the energy eaten.
+	kcalPerUnit float64
+}{
+	NutrientKcal:         {"energy", "kcal", 0},
+	NutrientProtein:      {"protein", "g", KcalPerGramProtein},
+	NutrientCarbs:        {"carbohydrates", "g", KcalPerGramCarbs},
+	NutrientNetCarbs:     {"net carbs", "g", KcalPerGramCarbs},
+	NutrientFat:          {"fat", "g", KcalPerGramFat},
+	NutrientSaturatedFat: {"saturated fat", "g", KcalPerGramFat},
+	NutrientFiber:        {"fiber", "g", 0},
+	NutrientSugar:        {"sugar", "g", KcalPerGramCarbs},
+	NutrientSodium:       {"sodium", "mg", 0},
+}
+
+// Valid reports whether the nutrient is known.
+func (n Nutrient) Valid() bool {
+	_, ok := nutrientInfo[n]
+	return ok
+}
+
+// HasEnergy reports whether the nutrient provides energy, so that rules
+// may bound it as a share of the energy eaten.
+func (n Nutrient) HasEnergy() bool {
+	return nutrientInfo[n].kcalPerUnit > 0
+}
+
+// RuleScope tells whether a rule applies to whole days or to single meals.
+type RuleScope string
+
+const (
+	ScopeDay  RuleScope = "day"
+	ScopeMeal RuleScope = "meal"
+)
+
+// Rule bounds a nutrient over each day, or each meal, of logged food. Min
+// and Max are amounts in the nutrient's unit, or percentages of the energy
+// eaten when PctKcal is set; either may be nil.
+type Rule struct {
+	Nutrient Nutrient
+	Scope    RuleScope
+	Min      *float64
+	Max      *float64
+	PctKcal  bool
+}
+
+// Violation is a day or a meal of logged entries that breaks a rule.
+type Violation struct {
+	Rule Rule
+	Date time.Time
+	// Meal is empty for day rules.
+	Meal mealmodel.MealType
+	// Value is the amount eaten, or its share of the energy, that is out
+	// of bounds.
+	Value float64
+	// AboveMax tells whether the rule's maximum was exceeded rather than
+	// its minimum missed. Value is rounded, so it cannot tell which.
+	AboveMax bool
+	EntryIDs []string
+}
+
+// Message describes the violation, e.g. "net carbs per day: 41.5 g, above
+// the maximum of 25 g".
+func (v Violation) Message() string {
+	r := v.Rule
+	info := nutrientInfo[r.Nutrient]
+	unit := " " + info.unit
+	if r.PctKcal {
+		unit = "% of energy"
+	}
+	bound, side := r.Min, "below the minimum"
+	if v.AboveMax {
+		bound, side = r.Max, "above the maximum"
+	}
+	return fmt.Sprintf("%s per %s: %s%s, %s of %s%s",
+		info.label, r.Scope, formatAmount(v.Value), unit, side, formatAmount(*bound), unit)
+}
+
+// mealOrder sorts the meals of a day.
+var mealOrder = map[mealmodel.MealType]int{
+	mealmodel.MealBreakfast: 0,
+	mealmodel.MealLunch:     1,
+	mealmodel.MealDinner:    2,
+	mealmodel.MealSnack:     3,
+}
+
+// CheckRules returns the rules the entries break, by date and then by meal.
+// Optional nutrients that some entries were logged without are taken as a
+// range of possible amounts, and a rule only counts as broken when the
+// whole range breaks it, so that missing values never raise false alarms.
+// Net carbs are the exception: entries without fiber count all their
+// carbohydrates.
+func CheckRules(rules []Rule, entries []mealmodel.Entry) []Violation {
+	sorted := make([]mealmodel.Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Date.Equal(sorted[j].Date) {
+			return sorted[i].Date.Before(sorted[j].Date)
+		}
+		return mealOrder[sorted[i].Meal] < mealOrder[sorted[j].Meal]
+	})
+
+	var violations []Violation
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].Date.Equal(sorted[start].Date) {
+			end++
+		}
+		dayEntries := sorted[start:end]
+		violations = append(violations, check(rules, ScopeDay, dayEntries)...)
+		for m := 0; m < len(dayEntries); {
+			n := m
+			for n < len(dayEntries) && dayEntries[n].Meal == dayEntries[m].Meal {
+				n++
+			}
+			violations = append(violations, check(rules, ScopeMeal, dayEntries[m:n])...)
+			m = n
+		}
+		start = end
+	}
+	return violations
+}
+
+// check applies the rules of the scope to the entries of a day or a meal.
+func check(rules []Rule, scope RuleScope, entries []mealmodel.Entry) []Violation {
+	var violations []Violation
+	for _, r := range rules {
+		if r.Scope != scope {
+			continue
+		}
+		lo, hi := amount(r.Nutrient, entries)
+		if r.PctKcal {
+			kcal, _ := amount(NutrientKcal, entries)
+			if kcal <= 0 {
+				continue
+			}
+			share := nutrientInfo[r.Nutrient].kcalPerUnit / kcal * 100
+			lo, hi = lo*share, hi*share
+		}
+
+		var value float64
+		var aboveMax bool
+		switch {
+		case r.Max != nil && lo > *r.Max:
+			value, aboveMax = lo, true
+		case r.Min != nil && hi < *r.Min:
+			value = hi
+		default:
+			continue
+		}
+		v := Violation{
+			Rule:     r,
+			Date:     entries[0].Date,
+			Value:    math.Round(value*10) / 10,
+			AboveMax: aboveMax,
+		}
+		if scope == ScopeMeal {
+			v.Meal = entries[0].Meal
+		}
+		for _, e := range entries {
+			v.EntryIDs = append(v.EntryIDs, e.ID)
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+// amount returns the least and the most of the nutrient the entries may
+// hold. The bounds differ when some entries lack the nutrient.
+func amount(n Nutrient, entries []mealmodel.Entry) (lo, hi float64) {
+	for i := range entries {
+		l, h := entryAmount(n, &entries[i])
+		lo, hi = lo+l, hi+h
+	}
+	return lo, hi
+}
+
+func entryAmount(n Nutrient, e *mealmodel.Entry) (lo, hi float64) {
+	optional := func(v *float64) (float64, float64) {
+		if v == nil {
+			return 0, math.Inf(1)
+		}
+		return *v, *v
+	}
+
+	switch n {
+	case NutrientKcal:
+		return e.Kcal, e.Kcal
+	case NutrientProtein:
+		return e.ProteinG, e.ProteinG
+	case NutrientCarbs:
+		return e.CarbsG, e.CarbsG
+	case NutrientFat:
+		return e.FatG, e.FatG
+	case NutrientNetCarbs:
+		// Without logged fiber, all carbohydrates count as net carbs.
+		net := e.CarbsG
+		if e.FiberG != nil {
+			net = math.Max(e.CarbsG-*e.FiberG, 0)
+		}
+		return net, net
+	case NutrientFiber:
+		return optional(e.FiberG)
+	case NutrientSugar:
+		return optional(e.SugarG)
+	case NutrientSaturatedFat:
+		return optional(e.SaturatedFatG)
+	case NutrientSodium:
+		return optional(e.SodiumMg)
+	}
+	return 0, 0
+}
+
+func formatAmount(x float64) string {
+	return strconv.FormatFloat(math.Round(x*10)/10, 'f', -1, 64)
+}
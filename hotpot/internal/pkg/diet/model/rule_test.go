@@ -0,0 +1,38 @@
+package model
+
+import (
+	mealmodel "hotpot/internal/pkg/meal/model"
+	"testing"
+	"time"
+)
+
+func TestCheckRulesOverageRoundedToBound(t *testing.T) {
+	maxNetCarbs := 25.0
+	minProtein := 100.0
+	rules := []Rule{
+		{Nutrient: NutrientNetCarbs, Scope: ScopeDay, Max: &maxNetCarbs},
+		{Nutrient: NutrientProtein, Scope: ScopeDay, Min: &minProtein},
+	}
+	entries := []mealmodel.Entry{{
+		ID:       "e1",
+		Date:     time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Meal:     mealmodel.MealLunch,
+		Kcal:     500,
+		ProteinG: 99.96,
+		CarbsG:   25.04,
+	}}
+
+	violations := CheckRules(rules, entries)
+	if len(violations) != 2 {
+		t.Fatalf("got %d violations, want 2", len(violations))
+	}
+	want := []string{
+		"net carbs per day: 25 g, above the maximum of 25 g",
+		"protein per day: 100 g, below the minimum of 100 g",
+	}
+	for i, v := range violations {
+		if got := v.Message(); got != want[i] {
+			t.Errorf("Message() = %q, want %q", got, want[i])
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Template is a curated diet, such as keto or DASH, that users copy into
+// personal diets. Its macro split is given in percent of the daily energy,
+// so it fits any energy target.
+type Template struct {
+	ID   string
+	Name string
+	// Summary is a one-line description, Description the full copy shown
+	// to users choosing a template.
+	Summary     string
+	Description string
+	Macros      MacroTargets
+	Rules       []Rule
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
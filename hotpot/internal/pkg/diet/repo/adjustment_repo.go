@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/diet/model"
+	"sort"
+	"sync"
+)
+
+// AdjustmentRepo persists the recalibrations of diets.
+type AdjustmentRepo interface {
+	Create(ctx context.Context, adjustment *model.Adjustment) error
+	// ListByDiet returns the adjustments of a diet, newest first.
+	ListByDiet(ctx context.Context, dietID string) ([]model.Adjustment, error)
+	DeleteByDiet(ctx context.Context, dietID string) error
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// MemoryAdjustmentRepo is an in-memory AdjustmentRepo.
+type MemoryAdjustmentRepo struct {
+	mu   sync.RWMutex
+	byID map[string]model.Adjustment
+}
+
+func NewMemoryAdjustmentRepo() *MemoryAdjustmentRepo {
+	return &MemoryAdjustmentRepo{
+		byID: make(map[string]model.Adjustment),
+	}
+}
+
+func (r *MemoryAdjustmentRepo) Create(_ context.Context, adjustment *model.Adjustment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[adjustment.ID] = *adjustment
+	return nil
+}
+
+func (r *MemoryAdjustmentRepo) ListByDiet(_ context.Context, dietID string) ([]model.Adjustment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var adjustments []model.Adjustment
+	for _, a := range r.byID {
+		if a.DietID == dietID {
+			adjustments = append(adjustments, a)
+		}
+	}
+	sort.Slice(adjustments, func(i, j int) bool {
+		return adjustments[i].CreatedAt.After(adjustments[j].CreatedAt)
+	})
+	return adjustments, nil
+}
+
+func (r *MemoryAdjustmentRepo) DeleteByDiet(_ context.Context, dietID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, a := range r.byID {
+		if a.DietID == dietID {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryAdjustmentRepo) DeleteByUser(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, a := range r.byID {
+		if a.UserID == userID {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/diet/model"
+	"sort"
+	"sync"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// Store groups the repositories used by the diet module.
+type Store struct {
+	Diets       DietRepo
+	Adjustments AdjustmentRepo
+	Plans       PlanRepo
+	Templates   TemplateRepo
+}
+
+// NewMemoryStore returns a Store backed entirely by in-memory repositories.
+// The template library starts with keto, Mediterranean, DASH and
+// high-protein diets.
+func NewMemoryStore() *Store {
+	return &Store{
+		Diets:       NewMemoryDietRepo(),
+		Adjustments: NewMemoryAdjustmentRepo(),
+		Plans:       NewMemoryPlanRepo(),
+		Templates:   NewMemoryTemplateRepo(starterTemplates()...),
+	}
+}
+
+// DietRepo persists diets.
+type DietRepo interface {
+	Create(ctx context.Context, diet *model.Diet) error
+	// Update stores the diet but keeps its stored active flag; only Activate
+	// changes which diet is active.
+	Update(ctx context.Context, diet *model.Diet) error
+	FindByID(ctx context.Context, id string) (*model.Diet, error)
+	// FindActive returns the user's active diet.
+	FindActive(ctx context.Context, userID string) (*model.Diet, error)
+	// ListByUser returns the user's diets, the most recent start date first.
+	ListByUser(ctx context.Context, userID string) ([]model.Diet, error)
+	// ListActive returns the active diet of every user.
+	ListActive(ctx context.Context) ([]model.Diet, error)
+	// Activate makes the diet the only active one of its user.
+	Activate(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// MemoryDietRepo is an in-memory DietRepo.
+type MemoryDietRepo struct {
+	mu   sync.RWMutex
+	byID map[string]model.Diet
+}
+
+func NewMemoryDietRepo() *MemoryDietRepo {
+	return &MemoryDietRepo{
+		byID: make(map[string]model.Diet),
+	}
+}
+
+func (r *MemoryDietRepo) Create(_ context.Context, diet *model.Diet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[diet.ID] = *diet
+	return nil
+}
+
+func (r *MemoryDietRepo) Update(_ context.Context, diet *model.Diet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byID[diet.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	updated := *diet
+	updated.Active = stored.Active
+	r.byID[diet.ID] = updated
+	return nil
+}
+
+func (r *MemoryDietRepo) FindByID(_ context.Context, id string) (*model.Diet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	diet, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &diet, nil
+}
+
+func (r *MemoryDietRepo) FindActive(_ context.Context, userID string) (*model.Diet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, diet := range r.byID {
+		if diet.UserID == userID && diet.Active {
+			return &diet, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryDietRepo) ListByUser(_ context.Context, userID string) ([]model.Diet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var diets []model.Diet
+	for _, diet := range r.byID {
+		if diet.UserID == userID {
+			diets = append(diets, diet)
+		}
+	}
+	sort.Slice(diets, func(i, j int) bool {
+		if !diets[i].StartDate.Equal(diets[j].StartDate) {
+			return diets[i].StartDate.After(diets[j].StartDate)
+		}
+		return diets[i].CreatedAt.After(diets[j].CreatedAt)
+	})
+	return diets, nil
+}
+
+func (r *MemoryDietRepo) ListActive(_ context.Context) ([]model.Diet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var diets []model.Diet
+	for _, diet := range r.byID {
+		if diet.Active {
+			diets = append(diets, diet)
+		}
+	}
+	return diets, nil
+}
+
+func (r *MemoryDietRepo) Activate(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, ok := r.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	for otherID, diet := range r.byID {
+		if diet.UserID == target.UserID && diet.Active != (otherID == id) {
+			diet.Active = otherID == id
+			r.byID[otherID] = diet
+		}
+	}
+	return nil
+}
+
+func (r *MemoryDietRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.byID, id)
+	return nil
+}
+
+func (r *MemoryDietRepo) DeleteByUser(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, diet := range r.byID {
+		if diet.UserID == userID {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/diet/model"
+	"sync"
+)
+
+// PlanRepo persists the latest meal plan of each diet.
+type PlanRepo interface {
+	// Save stores the plan, replacing the previous plan of its diet.
+	Save(ctx context.Context, plan *model.MealPlan) error
+	FindByDiet(ctx context.Context, dietID string) (*model.MealPlan, error)
+	DeleteByDiet(ctx context.Context, dietID string) error
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// MemoryPlanRepo is an in-memory PlanRepo.
+type MemoryPlanRepo struct {
+	mu     sync.RWMutex
+	byDiet map[string]model.MealPlan
+}
+
+func NewMemoryPlanRepo() *MemoryPlanRepo {
+	return &MemoryPlanRepo{
+		byDiet: make(map[string]model.MealPlan),
+	}
+}
+
+func (r *MemoryPlanRepo) Save(_ context.Context, plan *model.MealPlan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byDiet[plan.DietID] = *plan
+	return nil
+}
+
+func (r *MemoryPlanRepo) FindByDiet(_ context.Context, dietID string) (*model.MealPlan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plan, ok := r.byDiet[dietID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &plan, nil
+}
+
+func (r *MemoryPlanRepo) DeleteByDiet(_ context.Context, dietID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.byDiet, dietID)
+	return nil
+}
+
+func (r *MemoryPlanRepo) DeleteByUser(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for dietID, plan := range r.byDiet {
+		if plan.UserID == userID {
+			delete(r.byDiet, dietID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/diet/model"
+	"sort"
+	"sync"
+)
+
+// TemplateRepo persists diet templates.
+type TemplateRepo interface {
+	Create(ctx context.Context, template *model.Template) error
+	Update(ctx context.Context, template *model.Template) error
+	FindByID(ctx context.Context, id string) (*model.Template, error)
+	// List returns every template sorted by name.
+	List(ctx context.Context) ([]model.Template, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryTemplateRepo is an in-memory TemplateRepo.
+type MemoryTemplateRepo struct {
+	mu   sync.RWMutex
+	byID map[string]model.Template
+}
+
+// NewMemoryTemplateRepo returns a repository holding the given templates.
+func NewMemoryTemplateRepo(templates ...model.Template) *MemoryTemplateRepo {
+	r := &MemoryTemplateRepo{
+		byID: make(map[string]model.Template, len(templates)),
+	}
+	for _, template := range templates {
+		r.byID[template.ID] = template
+	}
+	return r
+}
+
+func (r *MemoryTemplateRepo) Create(_ context.Context, template *model.Template) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[template.ID] = *template
+	return nil
+}
+
+func (r *MemoryTemplateRepo) Update(_ context.Context, template *model.Template) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[template.ID]; !ok {
+		return ErrNotFound
+	}
+	r.byID[template.ID] = *template
+	return nil
+}
+
+func (r *MemoryTemplateRepo) FindByID(_ context.Context, id string) (*model.Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	template, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &template, nil
+}
+
+func (r *MemoryTemplateRepo) List(_ context.Context) ([]model.Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]model.Template, 0, len(r.byID))
+	for _, template := range r.byID {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+func (r *MemoryTemplateRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.byID, id)
+	return nil
+}
@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"hotpot/internal/pkg/diet/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// templateNamespace derives stable IDs for the starter templates from their
+// names, so diets keep pointing at the same templates across restarts.
+var templateNamespace = uuid.MustParse("c3a7f4d2-1e5b-4f0a-8b6d-2d9e7a4c1f35")
+
+// limit returns a pointer to a rule bound.
+func limit(v float64) *float64 {
+	return &v
+}
+
+// starterTemplates returns the templates the library starts with.
+func starterTemplates() []model.Template {
+	templates := []model.Template{
+		{
+			Name:    "Keto",
+			Summary: "Very low in carbohydrates and high in fat, to keep the body in ketosis.",
+			Description: "A ketogenic diet replaces most carbohydrates with fat. Bread, pasta, rice, " +
+				"potatoes, sugar and most fruit are out; meat, fish, eggs, cheese, nuts, oils and " +
+				"leafy vegetables are in. Keeping net carbs under about 25 g a day makes the body " +
+				"burn fat for fuel. Log fiber with your meals to have it left out of net carbs.",
+			Macros: model.MacroTargets{Unit: model.MacroPercent, Protein: 20, Carbs: 5, Fat: 75},
+			Rules: []model.Rule{
+				{Nutrient: model.NutrientNetCarbs, Scope: model.ScopeDay, Max: limit(25)},
+				{Nutrient: model.NutrientNetCarbs, Scope: model.ScopeMeal, Max: limit(10)},
+			},
+		},
+		{
+			Name:    "Mediterranean",
+			Summary: "Vegetables, legumes, whole grains, fish and olive oil, with little red meat and sugar.",
+			Description: "The Mediterranean diet follows the traditional cooking of southern Europe. " +
+				"Meals are built on vegetables, fruit, legumes, whole grains and nuts, with olive oil " +
+				"as the main fat, fish and poultry a few times a week, and red meat and sweets rarely. " +
+				"It is linked to a lower risk of heart disease.",
+			Macros: model.MacroTargets{Unit: model.MacroPercent, Protein: 20, Carbs: 45, Fat: 35},
+			Rules: []model.Rule{
+				{Nutrient: model.NutrientSaturatedFat, Scope: model.ScopeDay, Max: limit(10), PctKcal: true},
+				{Nutrient: model.NutrientSugar, Scope: model.ScopeDay, Max: limit(10), PctKcal: true},
+				{Nutrient: model.NutrientFiber, Scope: model.ScopeDay, Min: limit(25)},
+			},
+		},
+		{
+			Name:    "DASH",
+			Summary: "Dietary Approaches to Stop Hypertension: low in sodium and saturated fat.",
+			Description: "DASH was designed to lower blood pressure. It is rich in vegetables, fruit, " +
+				"low-fat dairy, whole grains, fish, poultry, beans and nuts, and limits salt, fatty " +
+				"meat, full-fat dairy and sugary drinks. Sodium is kept under 2,300 mg a day and " +
+				"saturated fat under 6% of the energy.",
+			Macros: model.MacroTargets{Unit: model.MacroPercent, Protein: 18, Carbs: 55, Fat: 27},
+			Rules: []model.Rule{
+				{Nutrient: model.NutrientSodium, Scope: model.ScopeDay, Max: limit(2300)},
+				{Nutrient: model.NutrientSaturatedFat, Scope: model.ScopeDay, Max: limit(6), PctKcal: true},
+				{Nutrient: model.NutrientFiber, Scope: model.ScopeDay, Min: limit(30)},
+			},
+		},
+		{
+			Name:    "High-protein",
+			Summary: "Plenty of protein at every meal, to keep muscle while losing fat or to build it.",
+			Description: "A high-protein diet gets about a third of its energy from protein: lean " +
+				"meat, fish, eggs, dairy, legumes and tofu. Protein keeps you full for longer and " +
+				"helps keep muscle during weight loss. Spreading it over the day's main meals works " +
+				"best, so lunch and dinner should each bring a good portion.",
+			Macros: model.MacroTargets{Unit: model.MacroPercent, Protein: 35, Carbs: 35, Fat: 30},
+			Rules: []model.Rule{
+				{Nutrient: model.NutrientProtein, Scope: model.ScopeDay, Min: limit(30), PctKcal: true},
+				{Nutrient: model.NutrientSugar, Scope: model.ScopeDay, Max: limit(50)},
+			},
+		},
+	}
+
+	now := time.Now().UTC()
+	for i := range templates {
+		templates[i].ID = uuid.NewSHA1(templateNamespace, []byte(templates[i].Name)).String()
+		templates[i].CreatedAt = now
+		templates[i].UpdatedAt = now
+	}
+	return templates
+}
@@ -2,19 +2,444 @@ package svc
 
 import (
 	"context"
+	"errors"
+	"hotpot/internal/core/cfg"
+	"hotpot/internal/core/utils/userdata"
+	"hotpot/internal/pkg/diet/model"
+	"hotpot/internal/pkg/diet/repo"
+	mealmodel "hotpot/internal/pkg/meal/model"
+	mealsvc "hotpot/internal/pkg/meal/svc"
+	usermodel "hotpot/internal/pkg/user/model"
+	usersvc "hotpot/internal/pkg/user/svc"
 	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDietNotFound   = errors.New("diet not found")
+	ErrNoActiveDiet   = errors.New("no active diet")
+	ErrInvalidDates   = errors.New("end date must not be before the start date")
+	ErrMacrosMismatch = errors.New("macro targets do not add up to the daily energy")
+	ErrAccessDenied   = errors.New("access denied")
 )
 
+// Macro targets rarely add up exactly after rounding. Gram targets may be
+// off the daily energy by gramTolerance, and percentages may sum to 100 give
+// or take percentTolerance points.
+const (
+	gramTolerance    = 0.1
+	percentTolerance = 1.0
+)
+
+// UserService is what the diet module needs from the user module.
+type UserService interface {
+	AuthorizeClientAccess(ctx context.Context, callerID, userID, resource string, access usermodel.CoachingAccess) error
+	EnergyNeeds(ctx context.Context, userID string, formula usersvc.Formula) (*usersvc.EnergyNeeds, error)
+	Measurements(ctx context.Context, userID string, from, to time.Time) ([]usersvc.MetricPoint, error)
+	Preferences(ctx context.Context, userID string) (*usermodel.Preferences, error)
+}
+
+// MealService is what the diet module needs from the meal module, which
+// keeps the food diary and the food catalog.
+type MealService interface {
+	DailyIntake(ctx context.Context, userID string, from, to time.Time) ([]mealmodel.DayIntake, error)
+	Entries(ctx context.Context, userID string, from, to time.Time) ([]mealmodel.Entry, error)
+	Foods(ctx context.Context, q mealsvc.FoodQuery) ([]mealmodel.Food, error)
+}
+
+// Caller is the user a request is made by.
+type Caller struct {
+	ID string
+	// Coach is set when the caller may work on their clients' data, which
+	// also takes the client's consent.
+	Coach bool
+}
+
 type DietSvc struct {
 	logger *slog.Logger
+	cfg    *cfg.Config
+	store  *repo.Store
+	users  UserService
+	meals  MealService
 }
 
-func NewDietService(logger *slog.Logger) *DietSvc {
+func NewDietService(
+	logger *slog.Logger,
+	config *cfg.Config,
+	store *repo.Store,
+	users UserService,
+	meals MealService,
+) *DietSvc {
 	return &DietSvc{
 		logger: logger,
+		cfg:    config,
+		store:  store,
+		users:  users,
+		meals:  meals,
 	}
 }
 
 func (svc *DietSvc) Ping(_ context.Context) (bool, error) {
 	return true, nil
 }
+
+// DietInput holds the fields of a new diet.
+type DietInput struct {
+	Name         string
+	Goal         model.Goal
+	StartDate    time.Time
+	EndDate      *time.Time
+	DailyKcal    float64
+	Macros       model.MacroTargets
+	WeeklyRateKg float64
+	Adaptive     bool
+	// TemplateID and Rules are set for diets created from a template.
+	TemplateID string
+	Rules      []model.Rule
+	// Activate makes the new diet the user's active diet.
+	Activate bool
+}
+
+// DietUpdate lists the diet fields to change. Nil fields are left as they
+// are; a non-nil EndDate pointing at the zero time removes the end date.
+type DietUpdate struct {
+	Name         *string
+	Goal         *model.Goal
+	StartDate    *time.Time
+	EndDate      *time.Time
+	DailyKcal    *float64
+	Macros       *model.MacroTargets
+	WeeklyRateKg *float64
+	Adaptive     *bool
+}
+
+// CreateDiet adds a diet for the user.
+func (svc *DietSvc) CreateDiet(ctx context.Context, caller Caller, userID string, in DietInput) (*model.Diet, error) {
+	if err := svc.authorize(ctx, caller, userID, usermodel.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	diet := &model.Diet{
+		ID:           uuid.NewString(),
+		UserID:       userID,
+		Name:         in.Name,
+		Goal:         in.Goal,
+		StartDate:    day(in.StartDate),
+		DailyKcal:    in.DailyKcal,
+		Macros:       in.Macros,
+		WeeklyRateKg: in.WeeklyRateKg,
+		Adaptive:     in.Adaptive,
+		TemplateID:   in.TemplateID,
+		Rules:        in.Rules,
+		CreatedBy:    caller.ID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if in.StartDate.IsZero() {
+		diet.StartDate = day(now)
+	}
+	if in.EndDate != nil {
+		end := day(*in.EndDate)
+		diet.EndDate = &end
+	}
+	if err := validate(diet); err != nil {
+		return nil, err
+	}
+
+	if err := svc.store.Diets.Create(ctx, diet); err != nil {
+		return nil, err
+	}
+	if in.Activate {
+		if err := svc.store.Diets.Activate(ctx, diet.ID); err != nil {
+			return nil, err
+		}
+		diet.Active = true
+	}
+	return diet, nil
+}
+
+// Diets returns the user's diets, the most recent start date first.
+func (svc *DietSvc) Diets(ctx context.Context, caller Caller, userID string) ([]model.Diet, error) {
+	if err := svc.authorize(ctx, caller, userID, usermodel.AccessRead); err != nil {
+		return nil, err
+	}
+	diets, err := svc.store.Diets.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if diets == nil {
+		diets = []model.Diet{}
+	}
+	return diets, nil
+}
+
+// Diet returns one diet.
+func (svc *DietSvc) Diet(ctx context.Context, caller Caller, id string) (*model.Diet, error) {
+	return svc.diet(ctx, caller, id, usermodel.AccessRead)
+}
+
+// ActiveDiet returns the diet the user's meals are currently measured against.
+func (svc *DietSvc) ActiveDiet(ctx context.Context, caller Caller, userID string) (*model.Diet, error) {
+	if err := svc.authorize(ctx, caller, userID, usermodel.AccessRead); err != nil {
+		return nil, err
+	}
+	diet, err := svc.store.Diets.FindActive(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNoActiveDiet
+		}
+		return nil, err
+	}
+	return diet, nil
+}
+
+// UpdateDiet applies the update to a diet and saves it.
+func (svc *DietSvc) UpdateDiet(ctx context.Context, caller Caller, id string, update DietUpdate) (*model.Diet, error) {
+	diet, err := svc.diet(ctx, caller, id, usermodel.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		diet.Name = *update.Name
+	}
+	if update.Goal != nil {
+		diet.Goal = *update.Goal
+	}
+	if update.StartDate != nil {
+		diet.StartDate = day(*update.StartDate)
+	}
+	if update.EndDate != nil {
+		if update.EndDate.IsZero() {
+			diet.EndDate = nil
+		} else {
+			end := day(*update.EndDate)
+			diet.EndDate = &end
+		}
+	}
+	if update.DailyKcal != nil {
+		diet.DailyKcal = *update.DailyKcal
+	}
+	if update.Macros != nil {
+		diet.Macros = *update.Macros
+	}
+	if update.WeeklyRateKg != nil {
+		diet.WeeklyRateKg = *update.WeeklyRateKg
+	}
+	if update.Adaptive != nil {
+		diet.Adaptive = *update.Adaptive
+	}
+	if err := validate(diet); err != nil {
+		return nil, err
+	}
+
+	diet.UpdatedAt = time.Now().UTC()
+	if err := svc.store.Diets.Update(ctx, diet); err != nil {
+		return nil, err
+	}
+	return diet, nil
+}
+
+// ActivateDiet makes the diet its user's active diet, deactivating the
+// previous one.
+func (svc *DietSvc) ActivateDiet(ctx context.Context, caller Caller, id string) (*model.Diet, error) {
+	diet, err := svc.diet(ctx, caller, id, usermodel.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.store.Diets.Activate(ctx, id); err != nil {
+		return nil, err
+	}
+	diet.Active = true
+	return diet, nil
+}
+
+// DeleteDiet removes a diet. Deleting the active diet leaves the user
+// without one.
+func (svc *DietSvc) DeleteDiet(ctx context.Context, caller Caller, id string) error {
+	if _, err := svc.diet(ctx, caller, id, usermodel.AccessWrite); err != nil {
+		return err
+	}
+	if err := svc.store.Adjustments.DeleteByDiet(ctx, id); err != nil {
+		return err
+	}
+	if err := svc.store.Plans.DeleteByDiet(ctx, id); err != nil {
+		return err
+	}
+	return svc.store.Diets.Delete(ctx, id)
+}
+
+type dietRecord struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Goal         string     `json:"goal"`
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      *time.Time `json:"end_date"`
+	DailyKcal    float64    `json:"daily_kcal"`
+	MacroUnit    string     `json:"macro_unit"`
+	Protein      float64    `json:"protein"`
+	Carbs        float64    `json:"carbs"`
+	Fat          float64    `json:"fat"`
+	WeeklyRateKg float64    `json:"weekly_rate_kg"`
+	Adaptive     bool       `json:"adaptive"`
+	TemplateID   string     `json:"template_id"`
+	Active       bool       `json:"active"`
+	CreatedBy    string     `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type adjustmentRecord struct {
+	ID                string    `json:"id"`
+	DietID            string    `json:"diet_id"`
+	WindowStart       time.Time `json:"window_start"`
+	WindowEnd         time.Time `json:"window_end"`
+	LoggedDays        int       `json:"logged_days"`
+	AvgIntakeKcal     float64   `json:"avg_intake_kcal"`
+	WeightChangeKg    float64   `json:"weight_change_kg"`
+	EstimatedTDEEKcal float64   `json:"estimated_tdee_kcal"`
+	PreviousKcal      float64   `json:"previous_kcal"`
+	DailyKcal         float64   `json:"daily_kcal"`
+	Explanation       string    `json:"explanation"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// ExportUserData returns the user's diets, their recalibrations and meal plans.
+func (svc *DietSvc) ExportUserData(ctx context.Context, userID string) ([]userdata.Dataset, error) {
+	diets, err := svc.store.Diets.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]dietRecord, len(diets))
+	for i, d := range diets {
+		records[i] = dietRecord{
+			ID:           d.ID,
+			Name:         d.Name,
+			Goal:         string(d.Goal),
+			StartDate:    d.StartDate,
+			EndDate:      d.EndDate,
+			DailyKcal:    d.DailyKcal,
+			MacroUnit:    string(d.Macros.Unit),
+			Protein:      d.Macros.Protein,
+			Carbs:        d.Macros.Carbs,
+			Fat:          d.Macros.Fat,
+			WeeklyRateKg: d.WeeklyRateKg,
+			Adaptive:     d.Adaptive,
+			TemplateID:   d.TemplateID,
+			Active:       d.Active,
+			CreatedBy:    d.CreatedBy,
+			CreatedAt:    d.CreatedAt,
+			UpdatedAt:    d.UpdatedAt,
+		}
+	}
+
+	var adjustments []adjustmentRecord
+	for _, d := range diets {
+		list, err := svc.store.Adjustments.ListByDiet(ctx, d.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range list {
+			adjustments = append(adjustments, adjustmentRecord{
+				ID:                a.ID,
+				DietID:            a.DietID,
+				WindowStart:       a.WindowStart,
+				WindowEnd:         a.WindowEnd,
+				LoggedDays:        a.LoggedDays,
+				AvgIntakeKcal:     a.AvgIntakeKcal,
+				WeightChangeKg:    a.WeightChangeKg,
+				EstimatedTDEEKcal: a.EstimatedTDEEKcal,
+				PreviousKcal:      a.PreviousKcal,
+				DailyKcal:         a.DailyKcal,
+				Explanation:       a.Explanation,
+				CreatedAt:         a.CreatedAt,
+			})
+		}
+	}
+
+	var plans []planRecord
+	for _, d := range diets {
+		plan, err := svc.store.Plans.FindByDiet(ctx, d.ID)
+		switch {
+		case err == nil:
+			plans = append(plans, planRecords(plan)...)
+		case !errors.Is(err, repo.ErrNotFound):
+			return nil, err
+		}
+	}
+
+	return []userdata.Dataset{
+		{Name: "diet/diets", Records: records},
+		{Name: "diet/adjustments", Records: adjustments},
+		{Name: "diet/meal_plans", Records: plans},
+	}, nil
+}
+
+// EraseUserData deletes everything the diet module stores about the user.
+func (svc *DietSvc) EraseUserData(ctx context.Context, userID string) error {
+	if err := svc.store.Adjustments.DeleteByUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := svc.store.Plans.DeleteByUser(ctx, userID); err != nil {
+		return err
+	}
+	return svc.store.Diets.DeleteByUser(ctx, userID)
+}
+
+// diet loads a diet the caller may access.
+func (svc *DietSvc) diet(ctx context.Context, caller Caller, id string, access usermodel.CoachingAccess) (*model.Diet, error) {
+	diet, err := svc.store.Diets.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrDietNotFound
+		}
+		return nil, err
+	}
+	if err := svc.authorize(ctx, caller, diet.UserID, access); err != nil {
+		return nil, err
+	}
+	return diet, nil
+}
+
+// authorize checks that the caller may access the user's diets: their own,
+// or those of a client who granted them enough access.
+func (svc *DietSvc) authorize(ctx context.Context, caller Caller, userID string, access usermodel.CoachingAccess) error {
+	if caller.ID != userID && !caller.Coach {
+		return ErrAccessDenied
+	}
+	err := svc.users.AuthorizeClientAccess(ctx, caller.ID, userID, usersvc.ResourceDiets, access)
+	if errors.Is(err, usersvc.ErrAccessDenied) {
+		return ErrAccessDenied
+	}
+	return err
+}
+
+// validate checks the consistency of a diet's dates and targets.
+func validate(diet *model.Diet) error {
+	if diet.EndDate != nil && diet.EndDate.Before(diet.StartDate) {
+		return ErrInvalidDates
+	}
+
+	m := diet.Macros
+	if m.Unit == model.MacroPercent {
+		if math.Abs(m.Protein+m.Carbs+m.Fat-100) > percentTolerance {
+			return ErrMacrosMismatch
+		}
+		return nil
+	}
+	if math.Abs(diet.MacroKcal()-diet.DailyKcal) > diet.DailyKcal*gramTolerance {
+		return ErrMacrosMismatch
+	}
+	return nil
+}
+
+// day truncates a time to its calendar date.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,187 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/diet/model"
+	usermodel "hotpot/internal/pkg/user/model"
+	usersvc "hotpot/internal/pkg/user/svc"
+	"math"
+	"time"
+)
+
+var ErrGoalUnreachable = errors.New("goal weight cannot be reached safely on the minimum energy intake")
+
+// kcalPerKg is the energy stored in a kilogram of body weight, mostly fat.
+const kcalPerKg = 7700
+
+// Safety limits of generated diets. Weight loss is capped at maxLossPct of
+// the body weight and at maxLossKg per week, weight gain at maxGainKg per
+// week, and the daily energy never drops below the BMR or the floor for
+// the user's sex.
+const (
+	maxLossPct     = 1.0
+	maxLossKg      = 1.0
+	maxGainKg      = 0.5
+	minKcalMale    = 1500
+	minKcalFemale  = 1200
+	maintainWithin = 0.5 // kg between current and goal weight treated as maintenance.
+)
+
+// Macro split of generated diets. Protein is set per kilogram of body weight
+// and capped at maxProteinPct of the energy, fat takes fatPct of the energy
+// and carbohydrates the rest.
+var proteinPerKg = map[model.Goal]float64{
+	model.GoalLose:     2.0,
+	model.GoalMaintain: 1.6,
+	model.GoalGain:     1.8,
+}
+
+const (
+	maxProteinPct = 35
+	fatPct        = 25
+)
+
+// Adjustments made to a generated diet so that it stays safe.
+const (
+	AdjustmentRateCapped = "rate_capped" // The weekly rate was lowered to the safe maximum.
+	AdjustmentKcalFloor  = "kcal_floor"  // The daily energy was raised to the minimum.
+)
+
+// lossLimits returns the fastest safe weekly weight loss and the least safe
+// daily energy for a user of the sex, weight and BMR. Without a known sex
+// or BMR the lower floor applies.
+func lossLimits(sex usermodel.Sex, weightKg, bmrKcal float64) (maxRateKg, minKcal float64) {
+	floor := minKcalFemale
+	if sex == usermodel.SexMale {
+		floor = minKcalMale
+	}
+	return min(maxLossKg, weightKg*maxLossPct/100), max(bmrKcal, float64(floor))
+}
+
+// GenerateInput holds the goal a diet is generated for.
+type GenerateInput struct {
+	Name         string
+	GoalWeightKg float64
+	// WeeklyRateKg is how fast to lose or gain weight; the direction
+	// follows from the goal weight.
+	WeeklyRateKg float64
+	// StartDate defaults to today.
+	StartDate time.Time
+	Formula   usersvc.Formula
+}
+
+// DietProposal is a generated diet that has not been saved. Its EndDate is
+// the estimated date the goal weight is reached.
+type DietProposal struct {
+	Diet         model.Diet
+	TDEEKcal     float64
+	WeightKg     float64
+	GoalWeightKg float64
+	// WeeklyRateKg is the rate the daily energy actually allows.
+	WeeklyRateKg float64
+	Adjustments  []string
+}
+
+// GenerateDiet proposes calorie and macro targets that take the user from
+// their current weight trend to the goal weight at the requested pace,
+// within safety limits. The proposal can be saved with CreateDiet.
+func (svc *DietSvc) GenerateDiet(ctx context.Context, caller Caller, userID string, in GenerateInput) (*DietProposal, error) {
+	if err := svc.authorize(ctx, caller, userID, usermodel.AccessRead); err != nil {
+		return nil, err
+	}
+	needs, err := svc.users.EnergyNeeds(ctx, userID, in.Formula)
+	if err != nil {
+		return nil, err
+	}
+
+	weight := needs.Input.WeightKg
+	p := &DietProposal{
+		TDEEKcal:     needs.TDEEKcal,
+		WeightKg:     math.Round(weight*10) / 10,
+		GoalWeightKg: in.GoalWeightKg,
+		Adjustments:  []string{},
+	}
+
+	diff := in.GoalWeightKg - weight
+	goal := model.GoalMaintain
+	rate := math.Abs(in.WeeklyRateKg)
+	kcal := needs.TDEEKcal
+	switch {
+	case math.Abs(diff) <= maintainWithin || rate == 0:
+		rate = 0
+	case diff < 0:
+		goal = model.GoalLose
+		maxRate, minKcal := lossLimits(needs.Input.Sex, weight, needs.BMRKcal)
+		if rate > maxRate {
+			rate = maxRate
+			p.Adjustments = append(p.Adjustments, AdjustmentRateCapped)
+		}
+		kcal = needs.TDEEKcal - rate*kcalPerKg/7
+		if kcal < minKcal {
+			if needs.TDEEKcal <= minKcal {
+				return nil, ErrGoalUnreachable
+			}
+			kcal = minKcal
+			rate = (needs.TDEEKcal - minKcal) * 7 / kcalPerKg
+			p.Adjustments = append(p.Adjustments, AdjustmentKcalFloor)
+		}
+	default:
+		goal = model.GoalGain
+		if rate > maxGainKg {
+			rate = maxGainKg
+			p.Adjustments = append(p.Adjustments, AdjustmentRateCapped)
+		}
+		kcal = needs.TDEEKcal + rate*kcalPerKg/7
+	}
+	kcal = math.Round(kcal/10) * 10
+	p.WeeklyRateKg = math.Round(rate*100) / 100
+
+	start := day(in.StartDate)
+	if in.StartDate.IsZero() {
+		start = day(time.Now().UTC())
+	}
+	p.Diet = model.Diet{
+		UserID:       userID,
+		Name:         in.Name,
+		Goal:         goal,
+		StartDate:    start,
+		DailyKcal:    kcal,
+		Macros:       macroSplit(goal, kcal, weight),
+		WeeklyRateKg: p.WeeklyRateKg,
+		Adaptive:     true,
+	}
+	if p.Diet.Name == "" {
+		p.Diet.Name = defaultName(goal)
+	}
+	if rate > 0 {
+		days := int(math.Ceil(math.Abs(diff) / rate * 7))
+		end := start.AddDate(0, 0, days)
+		p.Diet.EndDate = &end
+	}
+	return p, nil
+}
+
+// macroSplit returns gram targets for the goal and daily energy.
+func macroSplit(goal model.Goal, kcal, weightKg float64) model.MacroTargets {
+	protein := math.Round(min(proteinPerKg[goal]*weightKg, kcal*maxProteinPct/100/model.KcalPerGramProtein))
+	fat := math.Round(kcal * fatPct / 100 / model.KcalPerGramFat)
+	carbs := math.Round((kcal - protein*model.KcalPerGramProtein - fat*model.KcalPerGramFat) / model.KcalPerGramCarbs)
+	return model.MacroTargets{
+		Unit:    model.MacroGrams,
+		Protein: protein,
+		Carbs:   carbs,
+		Fat:     fat,
+	}
+}
+
+func defaultName(goal model.Goal) string {
+	switch goal {
+	case model.GoalLose:
+		return "Weight loss"
+	case model.GoalGain:
+		return "Weight gain"
+	default:
+		return "Maintenance"
+	}
+}
@@ -0,0 +1,458 @@
+package svc
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"hotpot/internal/pkg/diet/model"
+	"hotpot/internal/pkg/diet/repo"
+	mealmodel "hotpot/internal/pkg/meal/model"
+	mealsvc "hotpot/internal/pkg/meal/svc"
+	usermodel "hotpot/internal/pkg/user/model"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPlanNotFound   = errors.New("diet has no meal plan")
+	ErrNotEnoughFoods = errors.New("not enough catalog foods match the user's preferences")
+)
+
+const planDays = 7
+
+// Defaults of PlanInput.
+const (
+	DefaultTolerancePct = 10
+	DefaultMaxRepeats   = 2
+)
+
+// planSlots are the meals of a planned day and the share of the daily
+// energy each is meant to hold. Snacks may be left out.
+var planSlots = []struct {
+	meal      mealmodel.MealType
+	optional  bool
+	kcalShare float64
+}{
+	{mealmodel.MealBreakfast, false, 0.25},
+	{mealmodel.MealLunch, false, 0.325},
+	{mealmodel.MealDinner, false, 0.325},
+	{mealmodel.MealSnack, true, 0.05},
+	{mealmodel.MealSnack, true, 0.05},
+}
+
+// portions are the serving multiples a planned meal can take.
+var portions = []float64{0.5, 0.75, 1, 1.25, 1.5, 2}
+
+// Search effort per day: random starting points, and improvement passes
+// over the slots from each of them. Each slot only considers the
+// planCandidates foods that fit it best, so that the effort does not grow
+// with the catalog.
+const (
+	planRestarts   = 12
+	planPasses     = 20
+	planCandidates = 24
+)
+
+// PlanInput holds the options of a meal plan.
+type PlanInput struct {
+	// StartDate defaults to tomorrow.
+	StartDate time.Time
+	// TolerancePct is how far each day may be off the targets.
+	TolerancePct float64
+	// MaxDailyCost caps the cost of a day; nil means no cap.
+	MaxDailyCost *float64
+	// MaxRepeats is how often the same food may appear in the week.
+	MaxRepeats int
+}
+
+// PlanMeals builds a week of meals from the food catalog that meets the
+// diet's daily energy and macro targets within the tolerance. Foods that
+// clash with the user's allergens, restrictions or dislikes are left out,
+// no food appears more than MaxRepeats times, and each day stays within
+// the cost cap if one is set. Days that cannot meet all of this with the
+// foods left are planned as close as possible and flagged. The plan
+// replaces the diet's previous plan.
+func (svc *DietSvc) PlanMeals(ctx context.Context, caller Caller, dietID string, in PlanInput) (*model.MealPlan, error) {
+	diet, err := svc.diet(ctx, caller, dietID, usermodel.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+	foods, err := svc.plannableFoods(ctx, diet.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.TolerancePct == 0 {
+		in.TolerancePct = DefaultTolerancePct
+	}
+	if in.MaxRepeats == 0 {
+		in.MaxRepeats = DefaultMaxRepeats
+	}
+	start := day(in.StartDate)
+	if in.StartDate.IsZero() {
+		start = day(time.Now().UTC()).AddDate(0, 0, 1)
+	}
+
+	p := newPlanner(diet, foods, in)
+	if p == nil {
+		return nil, ErrNotEnoughFoods
+	}
+	plan := &model.MealPlan{
+		ID:           uuid.NewString(),
+		DietID:       diet.ID,
+		UserID:       diet.UserID,
+		StartDate:    start,
+		TolerancePct: in.TolerancePct,
+		MaxDailyCost: in.MaxDailyCost,
+		CreatedAt:    time.Now().UTC(),
+	}
+	for i := 0; i < planDays; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		plan.Days = append(plan.Days, p.planDay(start.AddDate(0, 0, i)))
+	}
+
+	if err := svc.store.Plans.Save(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// MealPlan returns the latest meal plan of a diet.
+func (svc *DietSvc) MealPlan(ctx context.Context, caller Caller, dietID string) (*model.MealPlan, error) {
+	if _, err := svc.diet(ctx, caller, dietID, usermodel.AccessRead); err != nil {
+		return nil, err
+	}
+	plan, err := svc.store.Plans.FindByDiet(ctx, dietID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrPlanNotFound
+		}
+		return nil, err
+	}
+	return plan, nil
+}
+
+// plannableFoods returns the catalog foods the user may be offered.
+func (svc *DietSvc) plannableFoods(ctx context.Context, userID string) ([]mealmodel.Food, error) {
+	all, err := svc.meals.Foods(ctx, mealsvc.FoodQuery{})
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := svc.users.Preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var foods []mealmodel.Food
+	for i := range all {
+		if len(prefs.Violations(all[i].PreferenceFood())) == 0 {
+			foods = append(foods, all[i])
+		}
+	}
+	return foods, nil
+}
+
+// choice is a food in a slot; a nil food leaves an optional slot empty.
+type choice struct {
+	food     *mealmodel.Food
+	servings float64
+}
+
+// planner picks meals day by day. Each day starts from random choices that
+// are improved one slot at a time, trying every food and portion, until no
+// change helps; the best of several starts is kept. The score adds up the
+// squared relative deviations from the targets, steep penalties beyond the
+// tolerance, the cost cap and the repeat limit, and small penalties for
+// repeats, which spread the week over more foods.
+type planner struct {
+	rng        *rand.Rand
+	candidates [][]*mealmodel.Food // Per slot.
+	target     [4]float64          // kcal, protein, carbs, fat.
+	tolerance  float64
+	maxCost    *float64
+	maxRepeats int
+	// used counts how often each food has been planned so far, and
+	// yesterday holds the foods of the previous day.
+	used      map[string]int
+	yesterday map[string]bool
+}
+
+func newPlanner(diet *model.Diet, foods []mealmodel.Food, in PlanInput) *planner {
+	protein, carbs, fat := diet.Grams()
+	p := &planner{
+		rng:        rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		target:     [4]float64{diet.DailyKcal, protein, carbs, fat},
+		tolerance:  in.TolerancePct / 100,
+		maxCost:    in.MaxDailyCost,
+		maxRepeats: in.MaxRepeats,
+		used:       map[string]int{},
+		yesterday:  map[string]bool{},
+	}
+	for _, slot := range planSlots {
+		var list []*mealmodel.Food
+		for i := range foods {
+			if slices.Contains(foods[i].Meals, slot.meal) {
+				list = append(list, &foods[i])
+			}
+		}
+		if len(list) == 0 && !slot.optional {
+			return nil
+		}
+		p.candidates = append(p.candidates, p.bestFits(list, slot.kcalShare))
+	}
+	return p
+}
+
+// bestFits returns the planCandidates foods that fit a slot best: those
+// whose energy, at the closest portion, is nearest the slot's share of the
+// target, and whose macro split is nearest the diet's.
+func (p *planner) bestFits(foods []*mealmodel.Food, kcalShare float64) []*mealmodel.Food {
+	if len(foods) <= planCandidates || p.target[0] <= 0 {
+		return foods
+	}
+	kcal := p.target[0] * kcalShare
+	misfit := make(map[*mealmodel.Food]float64, len(foods))
+	for _, f := range foods {
+		dev := math.Inf(1)
+		for _, s := range portions {
+			dev = math.Min(dev, math.Abs(f.Kcal*s-kcal)/kcal)
+		}
+		if f.Kcal > 0 {
+			split := [3]float64{
+				f.ProteinG * model.KcalPerGramProtein / f.Kcal,
+				f.CarbsG * model.KcalPerGramCarbs / f.Kcal,
+				f.FatG * model.KcalPerGramFat / f.Kcal,
+			}
+			perGram := [3]float64{model.KcalPerGramProtein, model.KcalPerGramCarbs, model.KcalPerGramFat}
+			for i := range split {
+				dev += math.Abs(split[i] - p.target[i+1]*perGram[i]/p.target[0])
+			}
+		}
+		misfit[f] = dev
+	}
+	sorted := slices.Clone(foods)
+	slices.SortStableFunc(sorted, func(a, b *mealmodel.Food) int {
+		return cmp.Compare(misfit[a], misfit[b])
+	})
+	return sorted[:planCandidates]
+}
+
+func (p *planner) planDay(date time.Time) model.PlanDay {
+	var best []choice
+	bestScore := math.Inf(1)
+	for r := 0; r < planRestarts; r++ {
+		choices := p.randomDay()
+		score := p.improve(choices)
+		if score < bestScore {
+			best, bestScore = choices, score
+		}
+	}
+
+	d := model.PlanDay{Date: date}
+	today := map[string]bool{}
+	repeated := false
+	for i, c := range best {
+		if c.food == nil {
+			continue
+		}
+		s := c.servings
+		d.Meals = append(d.Meals, model.PlannedMeal{
+			Meal:     planSlots[i].meal,
+			FoodID:   c.food.ID,
+			Name:     c.food.Name,
+			Servings: s,
+			Kcal:     math.Round(c.food.Kcal * s),
+			ProteinG: math.Round(c.food.ProteinG*s*10) / 10,
+			CarbsG:   math.Round(c.food.CarbsG*s*10) / 10,
+			FatG:     math.Round(c.food.FatG*s*10) / 10,
+			Cost:     math.Round(c.food.Cost*s*100) / 100,
+		})
+		p.used[c.food.ID]++
+		repeated = repeated || p.used[c.food.ID] > p.maxRepeats
+		today[c.food.ID] = true
+	}
+	totals, cost := sum(best)
+	d.Kcal = math.Round(totals[0])
+	d.ProteinG = math.Round(totals[1]*10) / 10
+	d.CarbsG = math.Round(totals[2]*10) / 10
+	d.FatG = math.Round(totals[3]*10) / 10
+	d.Cost = math.Round(cost*100) / 100
+	d.OnTarget = !repeated && p.onTarget(totals, cost)
+	p.yesterday = today
+	return d
+}
+
+func (p *planner) randomDay() []choice {
+	choices := make([]choice, len(planSlots))
+	for i, list := range p.candidates {
+		if len(list) == 0 || planSlots[i].optional && p.rng.IntN(2) == 0 {
+			continue
+		}
+		choices[i] = choice{food: list[p.rng.IntN(len(list))], servings: 1}
+	}
+	return choices
+}
+
+// improve changes one slot at a time to its best option until no change
+// lowers the score, and returns the final score.
+func (p *planner) improve(choices []choice) float64 {
+	score := p.score(choices)
+	for pass := 0; pass < planPasses; pass++ {
+		improved := false
+		for i := range choices {
+			current := choices[i]
+			options := p.options(i)
+			// Visit options in random order so that ties do not always
+			// go to the same food.
+			p.rng.Shuffle(len(options), func(a, b int) { options[a], options[b] = options[b], options[a] })
+			for _, o := range options {
+				choices[i] = o
+				if s := p.score(choices); s < score-1e-9 {
+					score, current, improved = s, o, true
+				}
+			}
+			choices[i] = current
+		}
+		if !improved {
+			break
+		}
+	}
+	return score
+}
+
+func (p *planner) options(slot int) []choice {
+	var options []choice
+	if planSlots[slot].optional {
+		options = append(options, choice{})
+	}
+	for _, f := range p.candidates[slot] {
+		for _, s := range portions {
+			options = append(options, choice{food: f, servings: s})
+		}
+	}
+	return options
+}
+
+// Weights of the score terms.
+var targetWeights = [4]float64{3, 2, 1, 1}
+
+const (
+	hardPenalty       = 100
+	toleranceWeight   = 20
+	repeatWeight      = 0.02
+	consecutiveWeight = 0.05
+	sameDayWeight     = 1
+	portionWeight     = 0.005
+)
+
+func (p *planner) score(choices []choice) float64 {
+	totals, cost := sum(choices)
+	var score float64
+	for i, t := range p.target {
+		if t <= 0 {
+			continue
+		}
+		dev := math.Abs(totals[i]-t) / t
+		score += targetWeights[i] * dev * dev
+		if dev > p.tolerance {
+			score += toleranceWeight * targetWeights[i] * (dev - p.tolerance)
+		}
+	}
+	if p.maxCost != nil && cost > *p.maxCost {
+		score += hardPenalty * (1 + (cost-*p.maxCost)/math.Max(*p.maxCost, 1))
+	}
+
+	for i, c := range choices {
+		if c.food == nil {
+			continue
+		}
+		id := c.food.ID
+		// Earlier slots holding the same food count as repeats today.
+		count := 1
+		for _, prev := range choices[:i] {
+			if prev.food != nil && prev.food.ID == id {
+				count++
+			}
+		}
+		if p.used[id]+count > p.maxRepeats {
+			score += hardPenalty
+		}
+		if count > 1 {
+			score += sameDayWeight
+		}
+		if p.yesterday[id] {
+			score += consecutiveWeight
+		}
+		score += repeatWeight*float64(p.used[id]) + portionWeight*math.Abs(c.servings-1)
+	}
+	return score
+}
+
+func (p *planner) onTarget(totals [4]float64, cost float64) bool {
+	for i, t := range p.target {
+		if t > 0 && math.Abs(totals[i]-t)/t > p.tolerance {
+			return false
+		}
+	}
+	return p.maxCost == nil || cost <= *p.maxCost
+}
+
+// sum returns the energy and macronutrients of the choices, in the order
+// of planner.target, and their cost.
+func sum(choices []choice) ([4]float64, float64) {
+	var totals [4]float64
+	var cost float64
+	for _, c := range choices {
+		if c.food == nil {
+			continue
+		}
+		totals[0] += c.food.Kcal * c.servings
+		totals[1] += c.food.ProteinG * c.servings
+		totals[2] += c.food.CarbsG * c.servings
+		totals[3] += c.food.FatG * c.servings
+		cost += c.food.Cost * c.servings
+	}
+	return totals, cost
+}
+
+// planRecord is one planned meal in data exports.
+type planRecord struct {
+	PlanID   string  `json:"plan_id"`
+	DietID   string  `json:"diet_id"`
+	Date     string  `json:"date"`
+	Meal     string  `json:"meal"`
+	Food     string  `json:"food"`
+	Servings float64 `json:"servings"`
+	Kcal     float64 `json:"kcal"`
+	ProteinG float64 `json:"protein_g"`
+	CarbsG   float64 `json:"carbs_g"`
+	FatG     float64 `json:"fat_g"`
+	Cost     float64 `json:"cost"`
+}
+
+func planRecords(plan *model.MealPlan) []planRecord {
+	var records []planRecord
+	for _, d := range plan.Days {
+		for _, m := range d.Meals {
+			records = append(records, planRecord{
+				PlanID:   plan.ID,
+				DietID:   plan.DietID,
+				Date:     d.Date.Format("2006-01-02"),
+				Meal:     string(m.Meal),
+				Food:     m.Name,
+				Servings: m.Servings,
+				Kcal:     m.Kcal,
+				ProteinG: m.ProteinG,
+				CarbsG:   m.CarbsG,
+				FatG:     m.FatG,
+				Cost:     m.Cost,
+			})
+		}
+	}
+	return records
+}
@@ -0,0 +1,258 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotpot/internal/pkg/diet/model"
+	usermodel "hotpot/internal/pkg/user/model"
+	usersvc "hotpot/internal/pkg/user/svc"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recalibration looks back over up to windowDays days of the diet and needs
+// at least minWindowDays of them, logged meals on minLoggedShare of those
+// days and weigh-ins at least minWeighInSpan apart.
+const (
+	windowDays     = 28
+	minWindowDays  = 14
+	minLoggedShare = 0.7
+	minWeighInSpan = 7 * 24 * time.Hour
+	// minAdjustmentKcal ignores changes too small to matter.
+	minAdjustmentKcal = 50
+)
+
+// Weekly rates recalibration aims for when the diet does not set one.
+var defaultWeeklyRate = map[model.Goal]float64{
+	model.GoalLose: 0.5,
+	model.GoalGain: 0.25,
+}
+
+// errNotEnoughData skips a diet until more meals and weigh-ins are logged.
+var errNotEnoughData = errors.New("not enough logged data")
+
+// Adjustments returns the recalibrations of a diet, newest first.
+func (svc *DietSvc) Adjustments(ctx context.Context, caller Caller, dietID string) ([]model.Adjustment, error) {
+	if _, err := svc.diet(ctx, caller, dietID, usermodel.AccessRead); err != nil {
+		return nil, err
+	}
+	adjustments, err := svc.store.Adjustments.ListByDiet(ctx, dietID)
+	if err != nil {
+		return nil, err
+	}
+	if adjustments == nil {
+		adjustments = []model.Adjustment{}
+	}
+	return adjustments, nil
+}
+
+// RecalibrateDiets adjusts the daily energy of every active adaptive diet
+// that is due. Formulas misjudge expenditure by hundreds of kcal, so the
+// real expenditure is estimated from the logged intake and the change of
+// the smoothed weight, and the target is moved towards what the goal rate
+// needs, within the configured bounds.
+func (svc *DietSvc) RecalibrateDiets(ctx context.Context) error {
+	diets, err := svc.store.Diets.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var errs []error
+	for i := range diets {
+		d := &diets[i]
+		if !d.Adaptive || d.RecalibratedAt != nil && now.Sub(*d.RecalibratedAt) < svc.cfg.DietRecalibrationPeriod {
+			continue
+		}
+		adjustment, err := svc.recalibrate(ctx, d, now)
+		switch {
+		case errors.Is(err, errNotEnoughData):
+			continue
+		case err != nil:
+			errs = append(errs, err)
+			svc.logger.Error("diet recalibration failed", "diet_id", d.ID, "error", err)
+			continue
+		}
+		if adjustment != nil {
+			svc.logger.Info("diet recalibrated", "diet_id", d.ID,
+				"previous_kcal", adjustment.PreviousKcal, "daily_kcal", adjustment.DailyKcal)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recalibrate checks one diet and saves it, with an adjustment when its
+// target changes enough.
+func (svc *DietSvc) recalibrate(ctx context.Context, d *model.Diet, now time.Time) (*model.Adjustment, error) {
+	// Today is still being logged, so the window ends yesterday.
+	end := day(now).AddDate(0, 0, -1)
+	start := end.AddDate(0, 0, 1-windowDays)
+	if start.Before(d.StartDate) {
+		start = d.StartDate
+	}
+	span := int(end.Sub(start).Hours()/24) + 1
+	if span < minWindowDays {
+		return nil, errNotEnoughData
+	}
+
+	days, err := svc.meals.DailyIntake(ctx, d.UserID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if float64(len(days)) < minLoggedShare*float64(span) {
+		return nil, errNotEnoughData
+	}
+	var intake float64
+	for _, day := range days {
+		intake += day.Kcal
+	}
+	intake /= float64(len(days))
+
+	points, err := svc.users.Measurements(ctx, d.UserID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	var trend []usersvc.MetricPoint
+	for _, p := range points {
+		if p.WeightTrend != nil {
+			trend = append(trend, p)
+		}
+	}
+	if len(trend) < 2 {
+		return nil, errNotEnoughData
+	}
+	first, last := trend[0], trend[len(trend)-1]
+	elapsed := last.MeasuredAt.Sub(first.MeasuredAt)
+	if elapsed < minWeighInSpan {
+		return nil, errNotEnoughData
+	}
+	change := *last.WeightTrend - *first.WeightTrend
+	tdee := intake - change*kcalPerKg/(elapsed.Hours()/24)
+
+	// The same safety limits as generated diets apply. Without a complete
+	// profile only the weight is known.
+	var sex usermodel.Sex
+	var bmr float64
+	needs, err := svc.users.EnergyNeeds(ctx, d.UserID, "")
+	var incomplete *usersvc.IncompleteProfileError
+	switch {
+	case err == nil:
+		sex, bmr = needs.Input.Sex, needs.BMRKcal
+	case !errors.As(err, &incomplete):
+		return nil, err
+	}
+	maxRate, minKcal := lossLimits(sex, *last.WeightTrend, bmr)
+
+	rate := d.WeeklyRateKg
+	if rate == 0 {
+		rate = defaultWeeklyRate[d.Goal]
+	}
+	desired := tdee
+	switch d.Goal {
+	case model.GoalLose:
+		rate = min(rate, maxRate)
+		desired -= rate * kcalPerKg / 7
+	case model.GoalGain:
+		desired += rate * kcalPerKg / 7
+	}
+
+	// The target is bounded first and the step towards it after, so that a
+	// diet below the floor climbs to it step by step too.
+	floor := math.Ceil(max(float64(svc.cfg.DietMinKcal), minKcal)/10) * 10
+	ceiling := float64(svc.cfg.DietMaxKcal)
+	target := math.Min(ceiling, math.Max(floor, desired))
+	maxStep := float64(svc.cfg.DietMaxAdjustmentKcal)
+	next := d.DailyKcal + math.Max(-maxStep, math.Min(maxStep, target-d.DailyKcal))
+	next = math.Round(next/10) * 10
+
+	d.RecalibratedAt = &now
+	if math.Abs(next-d.DailyKcal) < minAdjustmentKcal {
+		return nil, svc.store.Diets.Update(ctx, d)
+	}
+
+	a := &model.Adjustment{
+		ID:                uuid.NewString(),
+		DietID:            d.ID,
+		UserID:            d.UserID,
+		WindowStart:       start,
+		WindowEnd:         end,
+		LoggedDays:        len(days),
+		AvgIntakeKcal:     math.Round(intake),
+		WeightChangeKg:    math.Round(change*10) / 10,
+		EstimatedTDEEKcal: math.Round(tdee),
+		PreviousKcal:      d.DailyKcal,
+		DailyKcal:         next,
+		PreviousMacros:    d.Macros,
+		Macros:            rescaleMacros(d.Macros, d.DailyKcal, next),
+		CreatedAt:         now,
+	}
+	a.Explanation = explain(a, d.Goal, rate, desired, floor, ceiling)
+
+	d.DailyKcal = a.DailyKcal
+	d.Macros = a.Macros
+	d.UpdatedAt = now
+	if err := svc.store.Diets.Update(ctx, d); err != nil {
+		return nil, err
+	}
+	if err := svc.store.Adjustments.Create(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// rescaleMacros fits the macro targets to a new daily energy. Percentages
+// stay as they are. Gram targets keep the protein, which protects lean mass,
+// and scale carbohydrates and fat.
+func rescaleMacros(m model.MacroTargets, from, to float64) model.MacroTargets {
+	if m.Unit == model.MacroPercent {
+		return m
+	}
+	rest := m.Carbs*model.KcalPerGramCarbs + m.Fat*model.KcalPerGramFat
+	target := to - m.Protein*model.KcalPerGramProtein
+	if rest <= 0 || target <= 0 {
+		f := to / from
+		return model.MacroTargets{
+			Unit:    m.Unit,
+			Protein: math.Round(m.Protein * f),
+			Carbs:   math.Round(m.Carbs * f),
+			Fat:     math.Round(m.Fat * f),
+		}
+	}
+	f := target / rest
+	return model.MacroTargets{
+		Unit:    m.Unit,
+		Protein: m.Protein,
+		Carbs:   math.Round(m.Carbs * f),
+		Fat:     math.Round(m.Fat * f),
+	}
+}
+
+// explain tells the user in plain words why their target changed.
+func explain(a *model.Adjustment, goal model.Goal, rate, desired, minKcal, maxKcal float64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From %s to %s you logged meals on %d days, averaging %.0f kcal, and your weight trend changed by %+.1f kg. ",
+		a.WindowStart.Format("Jan 2"), a.WindowEnd.Format("Jan 2"), a.LoggedDays, a.AvgIntakeKcal, a.WeightChangeKg)
+	fmt.Fprintf(&b, "That puts your expenditure at about %.0f kcal a day. ", a.EstimatedTDEEKcal)
+	switch goal {
+	case model.GoalLose:
+		fmt.Fprintf(&b, "Losing %.2g kg a week takes about %.0f kcal a day", rate, desired)
+	case model.GoalGain:
+		fmt.Fprintf(&b, "Gaining %.2g kg a week takes about %.0f kcal a day", rate, desired)
+	default:
+		fmt.Fprintf(&b, "Keeping your weight takes about %.0f kcal a day", desired)
+	}
+	fmt.Fprintf(&b, ", so your target moves from %.0f to %.0f kcal.", a.PreviousKcal, a.DailyKcal)
+	switch {
+	case a.DailyKcal == minKcal:
+		fmt.Fprintf(&b, " It does not go below %.0f kcal for safety.", minKcal)
+	case a.DailyKcal == maxKcal:
+		fmt.Fprintf(&b, " It does not go above %.0f kcal.", maxKcal)
+	case math.Abs(desired-a.DailyKcal) >= 10:
+		b.WriteString(" Targets change step by step, so the rest follows at the next recalibration if the trend holds.")
+	}
+	return b.String()
+}
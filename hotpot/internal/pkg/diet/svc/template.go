@@ -0,0 +1,213 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/diet/model"
+	"hotpot/internal/pkg/diet/repo"
+	usermodel "hotpot/internal/pkg/user/model"
+	usersvc "hotpot/internal/pkg/user/svc"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("diet template not found")
+	ErrInvalidRule      = errors.New("each rule needs a known nutrient and scope, a minimum or a maximum not below it, and percentages only for nutrients with energy")
+)
+
+// TemplateInput holds the fields of a diet template. Macros are
+// percentages of the daily energy.
+type TemplateInput struct {
+	Name        string
+	Summary     string
+	Description string
+	Macros      model.MacroTargets
+	Rules       []model.Rule
+}
+
+// TemplateDietInput holds the personal settings of a diet created from a
+// template.
+type TemplateDietInput struct {
+	// Name defaults to the template's name and Goal to maintenance.
+	Name         string
+	Goal         model.Goal
+	StartDate    time.Time
+	EndDate      *time.Time
+	DailyKcal    float64
+	WeeklyRateKg float64
+	Adaptive     bool
+	Activate     bool
+}
+
+// Templates returns the template library sorted by name.
+func (svc *DietSvc) Templates(ctx context.Context) ([]model.Template, error) {
+	templates, err := svc.store.Templates.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		templates = []model.Template{}
+	}
+	return templates, nil
+}
+
+// Template returns one template.
+func (svc *DietSvc) Template(ctx context.Context, id string) (*model.Template, error) {
+	template, err := svc.store.Templates.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// CreateTemplate adds a template to the library.
+func (svc *DietSvc) CreateTemplate(ctx context.Context, in TemplateInput) (*model.Template, error) {
+	now := time.Now().UTC()
+	template := &model.Template{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+	}
+	if err := applyTemplateInput(template, in, now); err != nil {
+		return nil, err
+	}
+	if err := svc.store.Templates.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// UpdateTemplate replaces the fields of a template. Diets created from it
+// keep the targets and rules they were created with.
+func (svc *DietSvc) UpdateTemplate(ctx context.Context, id string, in TemplateInput) (*model.Template, error) {
+	template, err := svc.Template(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTemplateInput(template, in, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if err := svc.store.Templates.Update(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// DeleteTemplate removes a template from the library. Diets created from
+// it are kept.
+func (svc *DietSvc) DeleteTemplate(ctx context.Context, id string) error {
+	if err := svc.store.Templates.Delete(ctx, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrTemplateNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateDietFromTemplate adds a diet for the user with the template's macro
+// split and a copy of its rules.
+func (svc *DietSvc) CreateDietFromTemplate(ctx context.Context, caller Caller, userID, templateID string, in TemplateDietInput) (*model.Diet, error) {
+	template, err := svc.Template(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name == "" {
+		in.Name = template.Name
+	}
+	if in.Goal == "" {
+		in.Goal = model.GoalMaintain
+	}
+	return svc.CreateDiet(ctx, caller, userID, DietInput{
+		Name:         in.Name,
+		Goal:         in.Goal,
+		StartDate:    in.StartDate,
+		EndDate:      in.EndDate,
+		DailyKcal:    in.DailyKcal,
+		Macros:       template.Macros,
+		WeeklyRateKg: in.WeeklyRateKg,
+		Adaptive:     in.Adaptive,
+		TemplateID:   template.ID,
+		Rules:        slices.Clone(template.Rules),
+		Activate:     in.Activate,
+	})
+}
+
+// RuleViolations returns the rules of the diet that the user's logged meals
+// broke on days in [from, to] the diet ran.
+func (svc *DietSvc) RuleViolations(ctx context.Context, caller Caller, dietID string, from, to time.Time) ([]model.Violation, error) {
+	diet, err := svc.diet(ctx, caller, dietID, usermodel.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+	// Violations show the client's diary, which coaches need access to as well.
+	if caller.ID != diet.UserID {
+		err := svc.users.AuthorizeClientAccess(ctx, caller.ID, diet.UserID, usersvc.ResourceMeals, usermodel.AccessRead)
+		if errors.Is(err, usersvc.ErrAccessDenied) {
+			return nil, ErrAccessDenied
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	from, to = day(from), day(to)
+	if from.Before(diet.StartDate) {
+		from = diet.StartDate
+	}
+	if diet.EndDate != nil && to.After(*diet.EndDate) {
+		to = *diet.EndDate
+	}
+	if len(diet.Rules) == 0 || to.Before(from) {
+		return []model.Violation{}, nil
+	}
+
+	entries, err := svc.meals.Entries(ctx, diet.UserID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	violations := model.CheckRules(diet.Rules, entries)
+	if violations == nil {
+		violations = []model.Violation{}
+	}
+	return violations, nil
+}
+
+func applyTemplateInput(template *model.Template, in TemplateInput, now time.Time) error {
+	m := in.Macros
+	m.Unit = model.MacroPercent
+	if math.Abs(m.Protein+m.Carbs+m.Fat-100) > percentTolerance {
+		return ErrMacrosMismatch
+	}
+	for _, r := range in.Rules {
+		if err := validateRule(r); err != nil {
+			return err
+		}
+	}
+
+	template.Name = in.Name
+	template.Summary = in.Summary
+	template.Description = in.Description
+	template.Macros = m
+	template.Rules = in.Rules
+	template.UpdatedAt = now
+	return nil
+}
+
+func validateRule(r model.Rule) error {
+	switch {
+	case !r.Nutrient.Valid(),
+		r.Scope != model.ScopeDay && r.Scope != model.ScopeMeal,
+		r.Min == nil && r.Max == nil,
+		r.Min != nil && r.Max != nil && *r.Max < *r.Min,
+		r.PctKcal && !r.Nutrient.HasEnergy():
+		return ErrInvalidRule
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/meal/dto"
+	"hotpot/internal/pkg/meal/model"
+	"hotpot/internal/pkg/meal/svc"
+)
+
+func (c *MealCtrl) ListFoods(ctx *fiber.Ctx) error {
+	var q dto.FoodQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	foods, err := c.mealSvc.Foods(ctx.Context(), svc.FoodQuery{Search: q.Search, Meal: q.Meal})
+	if err != nil {
+		return c.foodError(ctx, err)
+	}
+	res := make([]dto.FoodRes, len(foods))
+	for i := range foods {
+		res[i] = toFoodRes(&foods[i])
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func (c *MealCtrl) GetFood(ctx *fiber.Ctx) error {
+	food, err := c.mealSvc.Food(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		return c.foodError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toFoodRes(food), 0, "")
+}
+
+func (c *MealCtrl) CreateFood(ctx *fiber.Ctx) error {
+	var req dto.FoodReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	food, err := c.mealSvc.CreateFood(ctx.Context(), toFoodInput(req))
+	if err != nil {
+		return c.foodError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Created, toFoodRes(food), 0, "")
+}
+
+func (c *MealCtrl) UpdateFood(ctx *fiber.Ctx) error {
+	var req dto.FoodReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	food, err := c.mealSvc.UpdateFood(ctx.Context(), ctx.Params("id"), toFoodInput(req))
+	if err != nil {
+		return c.foodError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toFoodRes(food), 0, "")
+}
+
+func (c *MealCtrl) DeleteFood(ctx *fiber.Ctx) error {
+	if err := c.mealSvc.DeleteFood(ctx.Context(), ctx.Params("id")); err != nil {
+		return c.foodError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+// foodError maps catalog errors to responses.
+func (c *MealCtrl) foodError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrFoodNotFound):
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	default:
+		c.logger.Error("catalog request failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
+
+func toFoodInput(req dto.FoodReq) svc.FoodInput {
+	return svc.FoodInput{
+		Kind:        req.Kind,
+		Name:        req.Name,
+		Meals:       req.Meals,
+		ServingDesc: req.ServingDesc,
+		Kcal:        req.Kcal,
+		ProteinG:    req.ProteinG,
+		CarbsG:      req.CarbsG,
+		FatG:        req.FatG,
+		Cost:        req.Cost,
+		Ingredients: req.Ingredients,
+		Allergens:   req.Allergens,
+		Labels:      req.Labels,
+	}
+}
+
+func toFoodRes(f *model.Food) dto.FoodRes {
+	return dto.FoodRes{
+		ID:          f.ID,
+		Kind:        f.Kind,
+		Name:        f.Name,
+		Meals:       f.Meals,
+		ServingDesc: f.ServingDesc,
+		Kcal:        f.Kcal,
+		ProteinG:    f.ProteinG,
+		CarbsG:      f.CarbsG,
+		FatG:        f.FatG,
+		Cost:        f.Cost,
+		Ingredients: f.Ingredients,
+		Allergens:   f.Allergens,
+		Labels:      f.Labels,
+		UpdatedAt:   f.UpdatedAt,
+	}
+}
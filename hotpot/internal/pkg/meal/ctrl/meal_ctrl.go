@@ -1,12 +1,21 @@
 package ctrl
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"hotpot/internal/core/utils/servers/http"
+	authmodel "hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/meal/dto"
+	"hotpot/internal/pkg/meal/model"
 	"hotpot/internal/pkg/meal/svc"
+	usermodel "hotpot/internal/pkg/user/model"
 	"log/slog"
+	"time"
 )
 
+const dateLayout = "2006-01-02"
+
 type MealCtrl struct {
 	logger  *slog.Logger
 	mealSvc *svc.MealSvc
@@ -26,3 +35,162 @@ func (c *MealCtrl) Ping(ctx *fiber.Ctx) error {
 	}
 	return http.NewResponse(ctx, http.OK, res, 0, "")
 }
+
+func (c *MealCtrl) CreateEntry(ctx *fiber.Ctx) error {
+	var q dto.DiaryQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+	var req dto.CreateEntryReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+	userID, err := c.owner(ctx, q.UserID, usermodel.AccessWrite)
+	if err != nil {
+		return c.entryError(ctx, err)
+	}
+
+	// The date is validated by the DTO.
+	date, _ := time.Parse(dateLayout, req.Date)
+	entry, err := c.mealSvc.LogEntry(ctx.Context(), userID, svc.EntryInput{
+		Date:          date,
+		Meal:          req.Meal,
+		Name:          req.Name,
+		Kcal:          req.Kcal,
+		ProteinG:      req.ProteinG,
+		CarbsG:        req.CarbsG,
+		FatG:          req.FatG,
+		FiberG:        req.FiberG,
+		SugarG:        req.SugarG,
+		SaturatedFatG: req.SaturatedFatG,
+		SodiumMg:      req.SodiumMg,
+	})
+	if err != nil {
+		return c.entryError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Created, toEntryRes(entry), 0, "")
+}
+
+func (c *MealCtrl) ListEntries(ctx *fiber.Ctx) error {
+	var q dto.EntryQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	userID, err := c.owner(ctx, q.UserID, usermodel.AccessRead)
+	if err != nil {
+		return c.entryError(ctx, err)
+	}
+
+	from, to := dateRange(q)
+	entries, err := c.mealSvc.Entries(ctx.Context(), userID, from, to)
+	if err != nil {
+		return c.entryError(ctx, err)
+	}
+	res := make([]dto.EntryRes, len(entries))
+	for i := range entries {
+		res[i] = toEntryRes(&entries[i])
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func (c *MealCtrl) DeleteEntry(ctx *fiber.Ctx) error {
+	var q dto.DiaryQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+	userID, err := c.owner(ctx, q.UserID, usermodel.AccessWrite)
+	if err != nil {
+		return c.entryError(ctx, err)
+	}
+
+	if err := c.mealSvc.DeleteEntry(ctx.Context(), userID, ctx.Params("id")); err != nil {
+		return c.entryError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *MealCtrl) Intake(ctx *fiber.Ctx) error {
+	var q dto.EntryQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	userID, err := c.owner(ctx, q.UserID, usermodel.AccessRead)
+	if err != nil {
+		return c.entryError(ctx, err)
+	}
+
+	from, to := dateRange(q)
+	days, err := c.mealSvc.DailyIntake(ctx.Context(), userID, from, to)
+	if err != nil {
+		return c.entryError(ctx, err)
+	}
+	res := make([]dto.DayIntakeRes, len(days))
+	for i, d := range days {
+		res[i] = dto.DayIntakeRes{
+			Date:     d.Date.Format(dateLayout),
+			Entries:  d.Entries,
+			Kcal:     d.Kcal,
+			ProteinG: d.ProteinG,
+			CarbsG:   d.CarbsG,
+			FatG:     d.FatG,
+		}
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+// entryError maps food diary errors to responses.
+func (c *MealCtrl) entryError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrEntryNotFound):
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	case errors.Is(err, svc.ErrAccessDenied):
+		return http.NewResponse(ctx, http.Forbidden, nil, http.CodeForbidden, err.Error())
+	default:
+		c.logger.Error("diary request failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
+
+// owner returns the user whose diary is requested, which defaults to the
+// caller, once the caller is allowed the access to it.
+func (c *MealCtrl) owner(ctx *fiber.Ctx, userID string, access usermodel.CoachingAccess) (string, error) {
+	caller := svc.Caller{
+		ID:    mw.UserID(ctx),
+		Coach: mw.HasPermission(ctx, authmodel.PermManageClients),
+	}
+	if userID == "" || userID == caller.ID {
+		return caller.ID, nil
+	}
+	return userID, c.mealSvc.Authorize(mw.RequestContext(ctx), caller, userID, access)
+}
+
+// dateRange parses the validated bounds of a query; missing ones are zero.
+func dateRange(q dto.EntryQuery) (from, to time.Time) {
+	if q.From != "" {
+		from, _ = time.Parse(dateLayout, q.From)
+	}
+	if q.To != "" {
+		to, _ = time.Parse(dateLayout, q.To)
+	}
+	return from, to
+}
+
+func toEntryRes(e *model.Entry) dto.EntryRes {
+	return dto.EntryRes{
+		ID:            e.ID,
+		Date:          e.Date.Format(dateLayout),
+		Meal:          e.Meal,
+		Name:          e.Name,
+		Kcal:          e.Kcal,
+		ProteinG:      e.ProteinG,
+		CarbsG:        e.CarbsG,
+		FatG:          e.FatG,
+		FiberG:        e.FiberG,
+		SugarG:        e.SugarG,
+		SaturatedFatG: e.SaturatedFatG,
+		SodiumMg:      e.SodiumMg,
+		CreatedAt:     e.CreatedAt,
+	}
+}
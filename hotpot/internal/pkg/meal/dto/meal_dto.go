@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"hotpot/internal/pkg/meal/model"
+	usermodel "hotpot/internal/pkg/user/model"
+	"time"
+)
+
+type CreateEntryReq struct {
+	// Date is the user's calendar date of the meal, formatted as YYYY-MM-DD.
+	Date     string         `json:"date" validate:"required,datetime=2006-01-02"`
+	Meal     model.MealType `json:"meal" validate:"required,oneof=breakfast lunch dinner snack"`
+	Name     string         `json:"name" validate:"required,max=200"`
+	Kcal     float64        `json:"kcal" validate:"gte=0,lte=10000"`
+	ProteinG float64        `json:"protein_g" validate:"gte=0,lte=1000"`
+	CarbsG   float64        `json:"carbs_g" validate:"gte=0,lte=2000"`
+	FatG     float64        `json:"fat_g" validate:"gte=0,lte=1000"`
+	// The nutrients below are optional; diet rules such as sodium limits
+	// are only checked against logged values.
+	FiberG        *float64 `json:"fiber_g" validate:"omitempty,gte=0,lte=500"`
+	SugarG        *float64 `json:"sugar_g" validate:"omitempty,gte=0,lte=2000"`
+	SaturatedFatG *float64 `json:"saturated_fat_g" validate:"omitempty,gte=0,lte=1000"`
+	SodiumMg      *float64 `json:"sodium_mg" validate:"omitempty,gte=0,lte=50000"`
+}
+
+// DiaryQuery selects whose food diary a request works on.
+type DiaryQuery struct {
+	// UserID selects a client's diary. It defaults to the caller.
+	UserID string `query:"user_id" validate:"omitempty,uuid"`
+}
+
+type EntryQuery struct {
+	// UserID selects a client's diary. It defaults to the caller.
+	UserID string `query:"user_id" validate:"omitempty,uuid"`
+	// From and To are dates formatted as YYYY-MM-DD; both are inclusive.
+	From string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To   string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type EntryRes struct {
+	ID            string         `json:"id"`
+	Date          string         `json:"date"`
+	Meal          model.MealType `json:"meal"`
+	Name          string         `json:"name"`
+	Kcal          float64        `json:"kcal"`
+	ProteinG      float64        `json:"protein_g"`
+	CarbsG        float64        `json:"carbs_g"`
+	FatG          float64        `json:"fat_g"`
+	FiberG        *float64       `json:"fiber_g"`
+	SugarG        *float64       `json:"sugar_g"`
+	SaturatedFatG *float64       `json:"saturated_fat_g"`
+	SodiumMg      *float64       `json:"sodium_mg"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type DayIntakeRes struct {
+	Date     string  `json:"date"`
+	Entries  int     `json:"entries"`
+	Kcal     float64 `json:"kcal"`
+	ProteinG float64 `json:"protein_g"`
+	CarbsG   float64 `json:"carbs_g"`
+	FatG     float64 `json:"fat_g"`
+}
+
+type FoodReq struct {
+	Kind        model.FoodKind          `json:"kind" validate:"required,oneof=food recipe"`
+	Name        string                  `json:"name" validate:"required,max=200"`
+	Meals       []model.MealType        `json:"meals" validate:"required,min=1,dive,oneof=breakfast lunch dinner snack"`
+	ServingDesc string                  `json:"serving" validate:"max=100"`
+	Kcal        float64                 `json:"kcal" validate:"gte=0,lte=5000"`
+	ProteinG    float64                 `json:"protein_g" validate:"gte=0,lte=500"`
+	CarbsG      float64                 `json:"carbs_g" validate:"gte=0,lte=1000"`
+	FatG        float64                 `json:"fat_g" validate:"gte=0,lte=500"`
+	Cost        float64                 `json:"cost" validate:"gte=0"`
+	Ingredients []string                `json:"ingredients" validate:"max=100,dive,required,max=100"`
+	Allergens   []usermodel.Allergen    `json:"allergens" validate:"max=14,dive,oneof=gluten crustaceans eggs fish peanuts soybeans milk nuts celery mustard sesame sulphites lupin molluscs"`
+	Labels      []usermodel.Restriction `json:"labels" validate:"max=5,dive,oneof=vegetarian vegan halal kosher gluten_free"`
+}
+
+type FoodQuery struct {
+	Search string         `query:"q" validate:"max=100"`
+	Meal   model.MealType `query:"meal" validate:"omitempty,oneof=breakfast lunch dinner snack"`
+}
+
+type FoodRes struct {
+	ID          string                  `json:"id"`
+	Kind        model.FoodKind          `json:"kind"`
+	Name        string                  `json:"name"`
+	Meals       []model.MealType        `json:"meals"`
+	ServingDesc string                  `json:"serving"`
+	Kcal        float64                 `json:"kcal"`
+	ProteinG    float64                 `json:"protein_g"`
+	CarbsG      float64                 `json:"carbs_g"`
+	FatG        float64                 `json:"fat_g"`
+	Cost        float64                 `json:"cost"`
+	Ingredients []string                `json:"ingredients"`
+	Allergens   []usermodel.Allergen    `json:"allergens"`
+	Labels      []usermodel.Restriction `json:"labels"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
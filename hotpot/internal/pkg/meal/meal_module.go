@@ -1,8 +1,13 @@
 package meal
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/userdata"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/mw"
 	"hotpot/internal/pkg/meal/ctrl"
+	"hotpot/internal/pkg/meal/repo"
 	"hotpot/internal/pkg/meal/svc"
 	"log/slog"
 )
@@ -12,18 +17,22 @@ type Module struct {
 	Version string
 
 	logger         *slog.Logger
+	authMw         *mw.AuthMw
 	MealController *ctrl.MealCtrl
+	// Service is shared with the diet module, which measures intake against targets.
+	Service *svc.MealSvc
 }
 
-func New(logger *slog.Logger) *Module {
+func New(logger *slog.Logger, authMw *mw.AuthMw, users svc.UserService) *Module {
+	mealSvc := svc.NewMealService(logger, repo.NewMemoryStore(), users)
+
 	mod := &Module{
-		Name:    "meal-module",
-		Version: "v1",
-		logger:  logger,
-		MealController: ctrl.NewMealController(
-			logger,
-			svc.NewMealService(logger),
-		),
+		Name:           "meal-module",
+		Version:        "v1",
+		logger:         logger,
+		authMw:         authMw,
+		MealController: ctrl.NewMealController(logger, mealSvc),
+		Service:        mealSvc,
 	}
 	return mod
 }
@@ -35,4 +44,29 @@ func (m *Module) InitHTTPRoutes(r fiber.Router) {
 
 	modGroup := root.Group("/meal")
 	modGroup.Get("/ping", m.MealController.Ping)
+
+	// Dietitians reach their clients' diaries with ?user_id=.
+	entryGroup := modGroup.Group("/entries", m.authMw.Authenticate)
+	entryGroup.Post("/", m.authMw.RequireScope(model.ScopeMealsWrite), m.MealController.CreateEntry)
+	entryGroup.Get("/", m.authMw.RequireScope(model.ScopeMealsRead), m.MealController.ListEntries)
+	entryGroup.Delete("/:id", m.authMw.RequireScope(model.ScopeMealsWrite), m.MealController.DeleteEntry)
+	modGroup.Get("/intake", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeMealsRead), m.MealController.Intake)
+
+	// The food catalog is shared by all users and curated by admins.
+	foodGroup := modGroup.Group("/foods", m.authMw.Authenticate)
+	foodGroup.Get("/", m.authMw.RequireScope(model.ScopeMealsRead), m.MealController.ListFoods)
+	foodGroup.Get("/:id", m.authMw.RequireScope(model.ScopeMealsRead), m.MealController.GetFood)
+	foodGroup.Post("/", m.authMw.RequireSession, m.authMw.RequireVerifiedEmail, m.authMw.RequirePermission(model.PermManageCatalog), m.MealController.CreateFood)
+	foodGroup.Put("/:id", m.authMw.RequireSession, m.authMw.RequireVerifiedEmail, m.authMw.RequirePermission(model.PermManageCatalog), m.MealController.UpdateFood)
+	foodGroup.Delete("/:id", m.authMw.RequireSession, m.authMw.RequireVerifiedEmail, m.authMw.RequirePermission(model.PermManageCatalog), m.MealController.DeleteFood)
+}
+
+// ExportUserData adds the user's food diary to data exports.
+func (m *Module) ExportUserData(ctx context.Context, userID string) ([]userdata.Dataset, error) {
+	return m.Service.ExportUserData(ctx, userID)
+}
+
+// EraseUserData removes the user's meal data when their account is deleted.
+func (m *Module) EraseUserData(ctx context.Context, userID string) error {
+	return m.Service.EraseUserData(ctx, userID)
 }
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// MealType is the meal of the day an entry belongs to.
+type MealType string
+
+const (
+	MealBreakfast MealType = "breakfast"
+	MealLunch     MealType = "lunch"
+	MealDinner    MealType = "dinner"
+	MealSnack     MealType = "snack"
+)
+
+// Entry is something the user ate, logged in their food diary.
+type Entry struct {
+	ID     string
+	UserID string
+	// Date is the user's calendar date of the meal at midnight UTC.
+	Date     time.Time
+	Meal     MealType
+	Name     string
+	Kcal     float64
+	ProteinG float64
+	CarbsG   float64
+	FatG     float64
+	// The nutrients below are optional and nil when they were not logged.
+	FiberG        *float64
+	SugarG        *float64
+	SaturatedFatG *float64
+	SodiumMg      *float64
+	// CreatedAt is when the entry was logged.
+	CreatedAt time.Time
+}
+
+// DayIntake is the total of a day's entries.
+type DayIntake struct {
+	Date     time.Time
+	Entries  int
+	Kcal     float64
+	ProteinG float64
+	CarbsG   float64
+	FatG     float64
+}
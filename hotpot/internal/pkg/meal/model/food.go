@@ -0,0 +1,48 @@
+package model
+
+import (
+	usermodel "hotpot/internal/pkg/user/model"
+	"time"
+)
+
+// FoodKind tells single foods from prepared dishes.
+type FoodKind string
+
+const (
+	KindFood   FoodKind = "food"
+	KindRecipe FoodKind = "recipe"
+)
+
+// Food is an item of the shared catalog that meal plans are built from:
+// a single food such as an apple, or a recipe. Nutrients and cost are
+// given per serving.
+type Food struct {
+	ID   string
+	Kind FoodKind
+	Name string
+	// Meals are the meals of the day the food suits.
+	Meals       []MealType
+	ServingDesc string
+	Kcal        float64
+	ProteinG    float64
+	CarbsG      float64
+	FatG        float64
+	// Cost is the price of a serving in the app's currency.
+	Cost        float64
+	Ingredients []string
+	Allergens   []usermodel.Allergen
+	// Labels are the dietary restrictions the food satisfies.
+	Labels    []usermodel.Restriction
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PreferenceFood describes the food for checks against a user's preferences.
+func (f *Food) PreferenceFood() usermodel.Food {
+	return usermodel.Food{
+		Name:        f.Name,
+		Ingredients: f.Ingredients,
+		Allergens:   f.Allergens,
+		Labels:      f.Labels,
+	}
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/meal/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// Store groups the repositories used by the meal module.
+type Store struct {
+	Entries EntryRepo
+	Foods   FoodRepo
+}
+
+// NewMemoryStore returns a Store backed entirely by in-memory repositories.
+// The catalog starts with a selection of common foods and dishes.
+func NewMemoryStore() *Store {
+	return &Store{
+		Entries: NewMemoryEntryRepo(),
+		Foods:   NewMemoryFoodRepo(starterFoods()...),
+	}
+}
+
+// EntryRepo persists food diary entries.
+type EntryRepo interface {
+	Create(ctx context.Context, entry *model.Entry) error
+	FindByID(ctx context.Context, id string) (*model.Entry, error)
+	// ListByUser returns the user's entries dated in [from, to], oldest
+	// first. Zero bounds are open.
+	ListByUser(ctx context.Context, userID string, from, to time.Time) ([]model.Entry, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// MemoryEntryRepo is an in-memory EntryRepo.
+type MemoryEntryRepo struct {
+	mu   sync.RWMutex
+	byID map[string]model.Entry
+}
+
+func NewMemoryEntryRepo() *MemoryEntryRepo {
+	return &MemoryEntryRepo{
+		byID: make(map[string]model.Entry),
+	}
+}
+
+func (r *MemoryEntryRepo) Create(_ context.Context, entry *model.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[entry.ID] = *entry
+	return nil
+}
+
+func (r *MemoryEntryRepo) FindByID(_ context.Context, id string) (*model.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &entry, nil
+}
+
+func (r *MemoryEntryRepo) ListByUser(_ context.Context, userID string, from, to time.Time) ([]model.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []model.Entry
+	for _, entry := range r.byID {
+		if entry.UserID != userID ||
+			!from.IsZero() && entry.Date.Before(from) ||
+			!to.IsZero() && entry.Date.After(to) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+func (r *MemoryEntryRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.byID, id)
+	return nil
+}
+
+func (r *MemoryEntryRepo) DeleteByUser(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, entry := range r.byID {
+		if entry.UserID == userID {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
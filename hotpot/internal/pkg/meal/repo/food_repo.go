@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/meal/model"
+	"sort"
+	"sync"
+)
+
+// FoodRepo persists the food catalog.
+type FoodRepo interface {
+	Create(ctx context.Context, food *model.Food) error
+	Update(ctx context.Context, food *model.Food) error
+	FindByID(ctx context.Context, id string) (*model.Food, error)
+	// List returns every food sorted by name.
+	List(ctx context.Context) ([]model.Food, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryFoodRepo is an in-memory FoodRepo.
+type MemoryFoodRepo struct {
+	mu   sync.RWMutex
+	byID map[string]model.Food
+}
+
+// NewMemoryFoodRepo returns a catalog holding the given foods.
+func NewMemoryFoodRepo(foods ...model.Food) *MemoryFoodRepo {
+	r := &MemoryFoodRepo{
+		byID: make(map[string]model.Food, len(foods)),
+	}
+	for _, food := range foods {
+		r.byID[food.ID] = food
+	}
+	return r
+}
+
+func (r *MemoryFoodRepo) Create(_ context.Context, food *model.Food) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[food.ID] = *food
+	return nil
+}
+
+func (r *MemoryFoodRepo) Update(_ context.Context, food *model.Food) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[food.ID]; !ok {
+		return ErrNotFound
+	}
+	r.byID[food.ID] = *food
+	return nil
+}
+
+func (r *MemoryFoodRepo) FindByID(_ context.Context, id string) (*model.Food, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	food, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &food, nil
+}
+
+func (r *MemoryFoodRepo) List(_ context.Context) ([]model.Food, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	foods := make([]model.Food, 0, len(r.byID))
+	for _, food := range r.byID {
+		foods = append(foods, food)
+	}
+	sort.Slice(foods, func(i, j int) bool {
+		return foods[i].Name < foods[j].Name
+	})
+	return foods, nil
+}
+
+func (r *MemoryFoodRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.byID, id)
+	return nil
+}
@@ -0,0 +1,149 @@
+package repo
+
+import (
+	"hotpot/internal/pkg/meal/model"
+	usermodel "hotpot/internal/pkg/user/model"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// foodNamespace derives stable IDs for the starter catalog from food names,
+// so plans keep pointing at the same foods across restarts.
+var foodNamespace = uuid.MustParse("5b0d8e0a-6f0c-4c56-9d1e-8f6a2f1c7e42")
+
+const (
+	breakfast = model.MealBreakfast
+	lunch     = model.MealLunch
+	dinner    = model.MealDinner
+	snack     = model.MealSnack
+)
+
+const (
+	vegan       = usermodel.RestrictionVegan
+	vegetarian  = usermodel.RestrictionVegetarian
+	glutenFree  = usermodel.RestrictionGlutenFree
+	celery      = usermodel.AllergenCelery
+	crustaceans = usermodel.AllergenCrustaceans
+	eggs        = usermodel.AllergenEggs
+	fish        = usermodel.AllergenFish
+	gluten      = usermodel.AllergenGluten
+	milk        = usermodel.AllergenMilk
+	mustard     = usermodel.AllergenMustard
+	nuts        = usermodel.AllergenNuts
+	peanuts     = usermodel.AllergenPeanuts
+	sesame      = usermodel.AllergenSesame
+	soybeans    = usermodel.AllergenSoybeans
+)
+
+type seed struct {
+	kind        model.FoodKind
+	name        string
+	meals       []model.MealType
+	serving     string
+	protein     float64
+	carbs       float64
+	fat         float64
+	cost        float64
+	ingredients []string
+	allergens   []usermodel.Allergen
+	labels      []usermodel.Restriction
+}
+
+// starterFoods returns the foods the catalog starts with. Energy is derived
+// from the macronutrients.
+func starterFoods() []model.Food {
+	seeds := []seed{
+		// Breakfast
+		{model.KindRecipe, "Oatmeal with banana and peanut butter", []model.MealType{breakfast}, "1 bowl", 12, 60, 12, 0.9,
+			[]string{"oats", "banana", "peanut butter"}, []usermodel.Allergen{gluten, peanuts}, []usermodel.Restriction{vegan}},
+		{model.KindRecipe, "Greek yogurt with berries and honey", []model.MealType{breakfast, snack}, "1 bowl", 20, 30, 5, 1.6,
+			[]string{"greek yogurt", "berries", "honey"}, []usermodel.Allergen{milk}, []usermodel.Restriction{vegetarian, glutenFree}},
+		{model.KindRecipe, "Scrambled eggs on toast", []model.MealType{breakfast}, "1 plate", 22, 30, 18, 1.2,
+			[]string{"eggs", "bread", "butter"}, []usermodel.Allergen{eggs, gluten, milk}, []usermodel.Restriction{vegetarian}},
+		{model.KindRecipe, "Tofu scramble with spinach", []model.MealType{breakfast}, "1 plate", 22, 10, 14, 1.5,
+			[]string{"tofu", "spinach", "onion", "olive oil"}, []usermodel.Allergen{soybeans}, []usermodel.Restriction{vegan, glutenFree}},
+		{model.KindRecipe, "Protein pancakes", []model.MealType{breakfast}, "3 pancakes", 30, 45, 8, 1.4,
+			[]string{"flour", "eggs", "milk", "whey protein"}, []usermodel.Allergen{gluten, eggs, milk}, []usermodel.Restriction{vegetarian}},
+		{model.KindRecipe, "Avocado toast with poached egg", []model.MealType{breakfast}, "2 slices", 14, 32, 20, 1.8,
+			[]string{"bread", "avocado", "eggs"}, []usermodel.Allergen{gluten, eggs}, []usermodel.Restriction{vegetarian}},
+		{model.KindRecipe, "Overnight chia oats", []model.MealType{breakfast}, "1 jar", 15, 50, 15, 1.3,
+			[]string{"oats", "chia seeds", "soy milk", "maple syrup"}, []usermodel.Allergen{gluten, soybeans}, []usermodel.Restriction{vegan}},
+
+		// Lunch
+		{model.KindRecipe, "Grilled chicken salad with quinoa", []model.MealType{lunch, dinner}, "1 bowl", 40, 40, 15, 3.5,
+			[]string{"chicken", "quinoa", "lettuce", "cucumber", "olive oil", "mustard"}, []usermodel.Allergen{mustard}, []usermodel.Restriction{glutenFree}},
+		{model.KindRecipe, "Turkey and hummus wrap", []model.MealType{lunch}, "1 wrap", 32, 45, 14, 3.0,
+			[]string{"turkey", "tortilla", "hummus", "lettuce"}, []usermodel.Allergen{gluten, sesame}, nil},
+		{model.KindRecipe, "Lentil soup with bread", []model.MealType{lunch, dinner}, "1 bowl", 20, 60, 8, 1.5,
+			[]string{"lentils", "carrot", "celery", "onion", "bread"}, []usermodel.Allergen{celery, gluten}, []usermodel.Restriction{vegan}},
+		{model.KindRecipe, "Tuna pasta salad", []model.MealType{lunch}, "1 bowl", 35, 55, 12, 2.5,
+			[]string{"tuna", "pasta", "mayonnaise", "sweetcorn"}, []usermodel.Allergen{fish, gluten, eggs}, nil},
+		{model.KindRecipe, "Chickpea curry with rice", []model.MealType{lunch, dinner}, "1 plate", 18, 75, 14, 2.0,
+			[]string{"chickpeas", "rice", "coconut milk", "tomato", "spinach"}, nil, []usermodel.Restriction{vegan, glutenFree}},
+		{model.KindRecipe, "Beef burrito bowl", []model.MealType{lunch, dinner}, "1 bowl", 38, 60, 18, 3.8,
+			[]string{"beef", "rice", "black beans", "cheese", "salsa"}, []usermodel.Allergen{milk}, []usermodel.Restriction{glutenFree}},
+		{model.KindRecipe, "Salmon poke bowl", []model.MealType{lunch}, "1 bowl", 32, 60, 16, 4.5,
+			[]string{"salmon", "rice", "soy sauce", "edamame", "sesame seeds"}, []usermodel.Allergen{fish, soybeans, sesame, gluten}, nil},
+
+		// Dinner
+		{model.KindRecipe, "Baked salmon with potatoes and broccoli", []model.MealType{dinner}, "1 plate", 35, 40, 20, 5.0,
+			[]string{"salmon", "potatoes", "broccoli", "olive oil"}, []usermodel.Allergen{fish}, []usermodel.Restriction{glutenFree}},
+		{model.KindRecipe, "Chicken stir-fry with rice", []model.MealType{dinner}, "1 plate", 38, 65, 12, 3.2,
+			[]string{"chicken", "rice", "peppers", "soy sauce"}, []usermodel.Allergen{soybeans, gluten}, nil},
+		{model.KindRecipe, "Spaghetti bolognese", []model.MealType{dinner}, "1 plate", 32, 75, 18, 2.8,
+			[]string{"beef", "spaghetti", "tomato", "celery", "carrot"}, []usermodel.Allergen{gluten, celery}, nil},
+		{model.KindRecipe, "Tofu stir-fry with noodles", []model.MealType{dinner}, "1 plate", 24, 60, 16, 2.4,
+			[]string{"tofu", "noodles", "broccoli", "soy sauce"}, []usermodel.Allergen{soybeans, gluten}, []usermodel.Restriction{vegan}},
+		{model.KindRecipe, "Turkey chili with beans", []model.MealType{lunch, dinner}, "1 bowl", 36, 40, 10, 2.6,
+			[]string{"turkey", "kidney beans", "tomato", "onion"}, nil, []usermodel.Restriction{glutenFree}},
+		{model.KindRecipe, "Shrimp paella", []model.MealType{dinner}, "1 plate", 30, 65, 12, 4.8,
+			[]string{"shrimp", "rice", "peas", "peppers"}, []usermodel.Allergen{crustaceans}, []usermodel.Restriction{glutenFree}},
+		{model.KindRecipe, "Black bean and sweet potato tacos", []model.MealType{lunch, dinner}, "3 tacos", 16, 70, 14, 2.0,
+			[]string{"black beans", "sweet potato", "corn tortillas", "avocado"}, nil, []usermodel.Restriction{vegan, glutenFree}},
+		{model.KindRecipe, "Steak with quinoa and asparagus", []model.MealType{dinner}, "1 plate", 45, 35, 20, 6.0,
+			[]string{"beef", "quinoa", "asparagus"}, nil, []usermodel.Restriction{glutenFree}},
+
+		// Snacks
+		{model.KindFood, "Apple", []model.MealType{snack}, "1 medium", 0.5, 25, 0.3, 0.4,
+			[]string{"apple"}, nil, []usermodel.Restriction{vegan, glutenFree}},
+		{model.KindFood, "Banana", []model.MealType{breakfast, snack}, "1 medium", 1.3, 27, 0.4, 0.3,
+			[]string{"banana"}, nil, []usermodel.Restriction{vegan, glutenFree}},
+		{model.KindFood, "Almonds", []model.MealType{snack}, "30 g", 6, 6, 15, 0.6,
+			[]string{"almonds"}, []usermodel.Allergen{nuts}, []usermodel.Restriction{vegan, glutenFree}},
+		{model.KindFood, "Cottage cheese", []model.MealType{snack}, "150 g", 18, 5, 4, 0.9,
+			[]string{"cottage cheese"}, []usermodel.Allergen{milk}, []usermodel.Restriction{vegetarian, glutenFree}},
+		{model.KindFood, "Protein shake", []model.MealType{breakfast, snack}, "1 scoop with milk", 25, 4, 2, 1.0,
+			[]string{"whey protein", "milk"}, []usermodel.Allergen{milk}, []usermodel.Restriction{vegetarian, glutenFree}},
+		{model.KindRecipe, "Hummus with carrot sticks", []model.MealType{snack}, "1 pot", 6, 18, 9, 0.8,
+			[]string{"hummus", "carrot"}, []usermodel.Allergen{sesame}, []usermodel.Restriction{vegan, glutenFree}},
+		{model.KindFood, "Hard-boiled eggs", []model.MealType{breakfast, snack}, "2 eggs", 12, 1, 10, 0.5,
+			[]string{"eggs"}, []usermodel.Allergen{eggs}, []usermodel.Restriction{vegetarian, glutenFree}},
+		{model.KindRecipe, "Rice cakes with peanut butter", []model.MealType{snack}, "2 cakes", 7, 22, 9, 0.5,
+			[]string{"rice cakes", "peanut butter"}, []usermodel.Allergen{peanuts}, []usermodel.Restriction{vegan, glutenFree}},
+	}
+
+	now := time.Now().UTC()
+	foods := make([]model.Food, len(seeds))
+	for i, s := range seeds {
+		foods[i] = model.Food{
+			ID:          uuid.NewSHA1(foodNamespace, []byte(s.name)).String(),
+			Kind:        s.kind,
+			Name:        s.name,
+			Meals:       s.meals,
+			ServingDesc: s.serving,
+			Kcal:        math.Round(s.protein*4 + s.carbs*4 + s.fat*9),
+			ProteinG:    s.protein,
+			CarbsG:      s.carbs,
+			FatG:        s.fat,
+			Cost:        s.cost,
+			Ingredients: s.ingredients,
+			Allergens:   s.allergens,
+			Labels:      s.labels,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+	return foods
+}
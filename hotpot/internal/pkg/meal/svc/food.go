@@ -0,0 +1,128 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/meal/model"
+	"hotpot/internal/pkg/meal/repo"
+	usermodel "hotpot/internal/pkg/user/model"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrFoodNotFound = errors.New("food not found")
+
+// FoodQuery filters the catalog. Empty fields match every food.
+type FoodQuery struct {
+	// Search matches names case-insensitively.
+	Search string
+	Meal   model.MealType
+}
+
+// FoodInput holds the fields of a catalog food.
+type FoodInput struct {
+	Kind        model.FoodKind
+	Name        string
+	Meals       []model.MealType
+	ServingDesc string
+	Kcal        float64
+	ProteinG    float64
+	CarbsG      float64
+	FatG        float64
+	Cost        float64
+	Ingredients []string
+	Allergens   []usermodel.Allergen
+	Labels      []usermodel.Restriction
+}
+
+// Foods returns the catalog foods matching the query, sorted by name. The
+// diet module builds meal plans from them.
+func (svc *MealSvc) Foods(ctx context.Context, q FoodQuery) ([]model.Food, error) {
+	all, err := svc.store.Foods.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	search := strings.ToLower(q.Search)
+	foods := []model.Food{}
+	for _, f := range all {
+		if q.Meal != "" && !slices.Contains(f.Meals, q.Meal) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(f.Name), search) {
+			continue
+		}
+		foods = append(foods, f)
+	}
+	return foods, nil
+}
+
+// Food returns one catalog food.
+func (svc *MealSvc) Food(ctx context.Context, id string) (*model.Food, error) {
+	food, err := svc.store.Foods.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrFoodNotFound
+		}
+		return nil, err
+	}
+	return food, nil
+}
+
+// CreateFood adds a food to the catalog.
+func (svc *MealSvc) CreateFood(ctx context.Context, in FoodInput) (*model.Food, error) {
+	now := time.Now().UTC()
+	food := &model.Food{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+	}
+	applyFoodInput(food, in, now)
+	if err := svc.store.Foods.Create(ctx, food); err != nil {
+		return nil, err
+	}
+	return food, nil
+}
+
+// UpdateFood replaces the fields of a catalog food.
+func (svc *MealSvc) UpdateFood(ctx context.Context, id string, in FoodInput) (*model.Food, error) {
+	food, err := svc.Food(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	applyFoodInput(food, in, time.Now().UTC())
+	if err := svc.store.Foods.Update(ctx, food); err != nil {
+		return nil, err
+	}
+	return food, nil
+}
+
+// DeleteFood removes a food from the catalog. Diary entries and saved meal
+// plans keep their copies of its name and nutrients.
+func (svc *MealSvc) DeleteFood(ctx context.Context, id string) error {
+	if err := svc.store.Foods.Delete(ctx, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrFoodNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func applyFoodInput(food *model.Food, in FoodInput, now time.Time) {
+	food.Kind = in.Kind
+	food.Name = in.Name
+	food.Meals = in.Meals
+	food.ServingDesc = in.ServingDesc
+	food.Kcal = in.Kcal
+	food.ProteinG = in.ProteinG
+	food.CarbsG = in.CarbsG
+	food.FatG = in.FatG
+	food.Cost = in.Cost
+	food.Ingredients = in.Ingredients
+	food.Allergens = in.Allergens
+	food.Labels = in.Labels
+	food.UpdatedAt = now
+}
@@ -2,19 +2,214 @@ package svc
 
 import (
 	"context"
+	"errors"
+	"hotpot/internal/core/utils/userdata"
+	"hotpot/internal/pkg/meal/model"
+	"hotpot/internal/pkg/meal/repo"
+	usermodel "hotpot/internal/pkg/user/model"
+	usersvc "hotpot/internal/pkg/user/svc"
 	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEntryNotFound = errors.New("diary entry not found")
+	ErrAccessDenied  = errors.New("access denied")
 )
 
+// UserService is what the meal module needs from the user module.
+type UserService interface {
+	AuthorizeClientAccess(ctx context.Context, callerID, userID, resource string, access usermodel.CoachingAccess) error
+}
+
+// Caller is the user making a request.
+type Caller struct {
+	ID string
+	// Coach is set when the caller may work on their clients' diaries, which
+	// also takes the client's consent.
+	Coach bool
+}
+
 type MealSvc struct {
 	logger *slog.Logger
+	store  *repo.Store
+	users  UserService
 }
 
-func NewMealService(logger *slog.Logger) *MealSvc {
+func NewMealService(logger *slog.Logger, store *repo.Store, users UserService) *MealSvc {
 	return &MealSvc{
 		logger: logger,
+		store:  store,
+		users:  users,
 	}
 }
 
 func (svc *MealSvc) Ping(_ context.Context) (bool, error) {
 	return true, nil
 }
+
+// EntryInput holds the fields of a new diary entry.
+type EntryInput struct {
+	Date     time.Time
+	Meal     model.MealType
+	Name     string
+	Kcal     float64
+	ProteinG float64
+	CarbsG   float64
+	FatG     float64
+	// Optional nutrients; nil when unknown.
+	FiberG        *float64
+	SugarG        *float64
+	SaturatedFatG *float64
+	SodiumMg      *float64
+}
+
+// Authorize checks that the caller may access the user's food diary: their
+// own, or that of a client who granted them enough access.
+func (svc *MealSvc) Authorize(ctx context.Context, caller Caller, userID string, access usermodel.CoachingAccess) error {
+	if caller.ID != userID && !caller.Coach {
+		return ErrAccessDenied
+	}
+	err := svc.users.AuthorizeClientAccess(ctx, caller.ID, userID, usersvc.ResourceMeals, access)
+	if errors.Is(err, usersvc.ErrAccessDenied) {
+		return ErrAccessDenied
+	}
+	return err
+}
+
+// LogEntry adds an entry to the user's food diary.
+func (svc *MealSvc) LogEntry(ctx context.Context, userID string, in EntryInput) (*model.Entry, error) {
+	entry := &model.Entry{
+		ID:            uuid.NewString(),
+		UserID:        userID,
+		Date:          day(in.Date),
+		Meal:          in.Meal,
+		Name:          in.Name,
+		Kcal:          in.Kcal,
+		ProteinG:      in.ProteinG,
+		CarbsG:        in.CarbsG,
+		FatG:          in.FatG,
+		FiberG:        in.FiberG,
+		SugarG:        in.SugarG,
+		SaturatedFatG: in.SaturatedFatG,
+		SodiumMg:      in.SodiumMg,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if err := svc.store.Entries.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Entries returns the user's entries dated in [from, to], oldest first.
+// Zero bounds are open.
+func (svc *MealSvc) Entries(ctx context.Context, userID string, from, to time.Time) ([]model.Entry, error) {
+	entries, err := svc.store.Entries.ListByUser(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []model.Entry{}
+	}
+	return entries, nil
+}
+
+// DeleteEntry removes one of the user's entries.
+func (svc *MealSvc) DeleteEntry(ctx context.Context, userID, id string) error {
+	entry, err := svc.store.Entries.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrEntryNotFound
+		}
+		return err
+	}
+	if entry.UserID != userID {
+		return ErrEntryNotFound
+	}
+	return svc.store.Entries.Delete(ctx, id)
+}
+
+// DailyIntake returns the totals of every day in [from, to] with at least
+// one entry, oldest first. Days without entries are left out rather than
+// counted as fasting, since they usually were not logged. The diet module
+// calls it to measure intake against targets.
+func (svc *MealSvc) DailyIntake(ctx context.Context, userID string, from, to time.Time) ([]model.DayIntake, error) {
+	entries, err := svc.store.Entries.ListByUser(ctx, userID, day(from), day(to))
+	if err != nil {
+		return nil, err
+	}
+
+	days := []model.DayIntake{}
+	for _, e := range entries {
+		if n := len(days); n == 0 || !days[n-1].Date.Equal(e.Date) {
+			days = append(days, model.DayIntake{Date: e.Date})
+		}
+		d := &days[len(days)-1]
+		d.Entries++
+		d.Kcal += e.Kcal
+		d.ProteinG += e.ProteinG
+		d.CarbsG += e.CarbsG
+		d.FatG += e.FatG
+	}
+	return days, nil
+}
+
+type entryRecord struct {
+	ID            string    `json:"id"`
+	Date          string    `json:"date"`
+	Meal          string    `json:"meal"`
+	Name          string    `json:"name"`
+	Kcal          float64   `json:"kcal"`
+	ProteinG      float64   `json:"protein_g"`
+	CarbsG        float64   `json:"carbs_g"`
+	FatG          float64   `json:"fat_g"`
+	FiberG        *float64  `json:"fiber_g"`
+	SugarG        *float64  `json:"sugar_g"`
+	SaturatedFatG *float64  `json:"saturated_fat_g"`
+	SodiumMg      *float64  `json:"sodium_mg"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ExportUserData returns the user's food diary.
+func (svc *MealSvc) ExportUserData(ctx context.Context, userID string) ([]userdata.Dataset, error) {
+	entries, err := svc.store.Entries.ListByUser(ctx, userID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]entryRecord, len(entries))
+	for i, e := range entries {
+		records[i] = entryRecord{
+			ID:            e.ID,
+			Date:          e.Date.Format("2006-01-02"),
+			Meal:          string(e.Meal),
+			Name:          e.Name,
+			Kcal:          e.Kcal,
+			ProteinG:      e.ProteinG,
+			CarbsG:        e.CarbsG,
+			FatG:          e.FatG,
+			FiberG:        e.FiberG,
+			SugarG:        e.SugarG,
+			SaturatedFatG: e.SaturatedFatG,
+			SodiumMg:      e.SodiumMg,
+			CreatedAt:     e.CreatedAt,
+		}
+	}
+	return []userdata.Dataset{{Name: "meal/diary", Records: records}}, nil
+}
+
+// EraseUserData deletes everything the meal module stores about the user.
+func (svc *MealSvc) EraseUserData(ctx context.Context, userID string) error {
+	return svc.store.Entries.DeleteByUser(ctx, userID)
+}
+
+// day truncates a time to its calendar date.
+func day(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
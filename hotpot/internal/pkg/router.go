@@ -1,8 +1,12 @@
 package pkg
 
 import (
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
+	"hotpot/internal/core/cfg"
+	"hotpot/internal/core/utils/servers/scheduler"
+	"hotpot/internal/core/utils/userdata"
 	"hotpot/internal/pkg/auth"
 	"hotpot/internal/pkg/diet"
 	"hotpot/internal/pkg/meal"
@@ -14,25 +18,45 @@ type Module interface {
 	InitHTTPRoutes(r fiber.Router)
 }
 
+// JobModule is implemented by modules that run background jobs.
+type JobModule interface {
+	InitJobs(s *scheduler.Scheduler)
+}
+
 type Router struct {
 	logger  *slog.Logger
 	modules []Module
+	// userData collects the modules that store data about users, for
+	// privacy requests such as data exports.
+	userData *userdata.Registry
 }
 
-func NewRouter(logger *slog.Logger) *Router {
-	return &Router{
-		logger: logger,
-		modules: []Module{
-			auth.New(logger),
-			user.New(logger),
-			diet.New(logger),
-			meal.New(logger),
-		},
+// NewRouter creates every module. It fails when a module cannot start with
+// its configuration.
+func NewRouter(logger *slog.Logger, config *cfg.Config) (*Router, error) {
+	r := &Router{
+		logger:   logger,
+		userData: userdata.NewRegistry(),
+	}
+
+	authMod := auth.New(logger, config)
+	r.RegisterModule(authMod)
+	userMod, err := user.New(logger, config, authMod.Middleware, authMod.Service, authMod.Mailer, r.userData)
+	if err != nil {
+		return nil, fmt.Errorf("user module: %w", err)
 	}
+	r.RegisterModule(userMod)
+	mealMod := meal.New(logger, authMod.Middleware, userMod.Service)
+	r.RegisterModule(diet.New(logger, config, authMod.Middleware, userMod.Service, mealMod.Service))
+	r.RegisterModule(mealMod)
+	return r, nil
 }
 
+// RegisterModule adds a module, and hooks it into data exports and account
+// deletion when it implements userdata.Exporter or userdata.Eraser.
 func (r *Router) RegisterModule(module Module) {
 	r.modules = append(r.modules, module)
+	r.userData.Register(module)
 }
 
 func (r *Router) Init(app *fiber.App) {
@@ -42,3 +66,12 @@ func (r *Router) Init(app *fiber.App) {
 		module.InitHTTPRoutes(app)
 	}
 }
+
+// InitJobs adds the background jobs of every module to the scheduler.
+func (r *Router) InitJobs(s *scheduler.Scheduler) {
+	for _, module := range r.modules {
+		if m, ok := module.(JobModule); ok {
+			m.InitJobs(s)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package ctrl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+)
+
+// avatarField is the multipart form field carrying the picture.
+const avatarField = "avatar"
+
+func (c *UserCtrl) UploadAvatar(ctx *fiber.Ctx) error {
+	header, err := ctx.FormFile(avatarField)
+	if err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError,
+			"A picture is required in the '"+avatarField+"' form field")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return c.profileError(ctx, err)
+	}
+	defer file.Close()
+
+	profile, err := c.userSvc.UploadAvatar(ctx.Context(), mw.UserID(ctx), file)
+	if err != nil {
+		return c.profileError(ctx, err)
+	}
+	return c.sendProfile(ctx, profile)
+}
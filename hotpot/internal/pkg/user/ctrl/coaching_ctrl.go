@@ -0,0 +1,209 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/user/dto"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/svc"
+)
+
+func (c *UserCtrl) InviteClient(ctx *fiber.Ctx) error {
+	var req dto.InviteClientReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	link, code, err := c.userSvc.Invite(mw.RequestContext(ctx), mw.UserID(ctx), req.Email, req.Access)
+	if err != nil {
+		return c.coachingError(ctx, err)
+	}
+	res := toInvitationRes(link)
+	res.Code = code
+	return http.NewResponse(ctx, http.Created, res, 0, "")
+}
+
+func (c *UserCtrl) ListInvitations(ctx *fiber.Ctx) error {
+	links, err := c.userSvc.Invitations(mw.RequestContext(ctx), mw.UserID(ctx))
+	if err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toInvitationResList(links), 0, "")
+}
+
+func (c *UserCtrl) CancelInvitation(ctx *fiber.Ctx) error {
+	if err := c.userSvc.CancelInvitation(mw.RequestContext(ctx), mw.UserID(ctx), ctx.Params("id")); err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *UserCtrl) ListClients(ctx *fiber.Ctx) error {
+	links, err := c.userSvc.Clients(mw.RequestContext(ctx), mw.UserID(ctx))
+	if err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toCoachingResList(links), 0, "")
+}
+
+func (c *UserCtrl) EndClient(ctx *fiber.Ctx) error {
+	if err := c.userSvc.EndCoaching(mw.RequestContext(ctx), mw.UserID(ctx), ctx.Params("id")); err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *UserCtrl) ClientProfile(ctx *fiber.Ctx) error {
+	clientID := ctx.Params("id")
+	if err := c.userSvc.AuthorizeClientAccess(mw.RequestContext(ctx), mw.UserID(ctx), clientID, svc.ResourceProfile, model.AccessRead); err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return c.profile(ctx, clientID)
+}
+
+func (c *UserCtrl) ClientMeasurements(ctx *fiber.Ctx) error {
+	var q dto.MeasurementQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	clientID := ctx.Params("id")
+	if err := c.userSvc.AuthorizeClientAccess(mw.RequestContext(ctx), mw.UserID(ctx), clientID, svc.ResourceMetrics, model.AccessRead); err != nil {
+		return c.coachingError(ctx, err)
+	}
+	points, err := c.userSvc.Measurements(mw.RequestContext(ctx), clientID, q.From, q.To)
+	if err != nil {
+		return c.measurementError(ctx, err)
+	}
+
+	res := make([]dto.MeasurementRes, len(points))
+	for i, p := range points {
+		res[i] = toMeasurementRes(p)
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func (c *UserCtrl) CreateClientMeasurement(ctx *fiber.Ctx) error {
+	var req dto.CreateMeasurementReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	clientID := ctx.Params("id")
+	if err := c.userSvc.AuthorizeClientAccess(mw.RequestContext(ctx), mw.UserID(ctx), clientID, svc.ResourceMetrics, model.AccessWrite); err != nil {
+		return c.coachingError(ctx, err)
+	}
+	m, err := c.userSvc.RecordMeasurement(mw.RequestContext(ctx), clientID, req.MeasuredAt, req.WeightKg, req.BodyFatPct, req.WaistCm)
+	if err != nil {
+		return c.measurementError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Created, toMeasurementRes(svc.MetricPoint{Measurement: *m}), 0, "")
+}
+
+func (c *UserCtrl) ListCoaches(ctx *fiber.Ctx) error {
+	links, err := c.userSvc.Coaches(mw.RequestContext(ctx), mw.UserID(ctx))
+	if err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toCoachingResList(links), 0, "")
+}
+
+func (c *UserCtrl) ReceivedInvitations(ctx *fiber.Ctx) error {
+	links, err := c.userSvc.ReceivedInvitations(mw.RequestContext(ctx), mw.UserID(ctx))
+	if err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toInvitationResList(links), 0, "")
+}
+
+func (c *UserCtrl) AcceptInvitation(ctx *fiber.Ctx) error {
+	var req dto.AcceptInvitationReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	var (
+		link *model.CoachingLink
+		err  error
+	)
+	if req.InvitationID != "" {
+		link, err = c.userSvc.AcceptInvitation(mw.RequestContext(ctx), mw.UserID(ctx), req.InvitationID)
+	} else {
+		link, err = c.userSvc.RedeemInvitationCode(mw.RequestContext(ctx), mw.UserID(ctx), req.Code)
+	}
+	if err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Created, toCoachingRes(link), 0, "")
+}
+
+func (c *UserCtrl) DeclineInvitation(ctx *fiber.Ctx) error {
+	if err := c.userSvc.DeclineInvitation(mw.RequestContext(ctx), mw.UserID(ctx), ctx.Params("id")); err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+func (c *UserCtrl) EndCoach(ctx *fiber.Ctx) error {
+	if err := c.userSvc.EndCoaching(mw.RequestContext(ctx), ctx.Params("id"), mw.UserID(ctx)); err != nil {
+		return c.coachingError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+// coachingError maps coach-client relationship errors to responses.
+func (c *UserCtrl) coachingError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrInvitationNotFound),
+		errors.Is(err, svc.ErrInvitationExpired),
+		errors.Is(err, svc.ErrCoachingNotFound):
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	case errors.Is(err, svc.ErrSelfCoaching):
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	case errors.Is(err, svc.ErrAlreadyCoached):
+		return http.NewResponse(ctx, http.Conflict, nil, http.CodeAlreadyExists, err.Error())
+	case errors.Is(err, svc.ErrAccessDenied):
+		return http.NewResponse(ctx, http.Forbidden, nil, http.CodeForbidden, err.Error())
+	default:
+		c.logger.Error("coaching request failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
+
+func toInvitationRes(link *model.CoachingLink) dto.InvitationRes {
+	return dto.InvitationRes{
+		ID:        link.ID,
+		CoachID:   link.CoachID,
+		Email:     link.InviteEmail,
+		Access:    link.Access,
+		CreatedAt: link.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+	}
+}
+
+func toInvitationResList(links []model.CoachingLink) []dto.InvitationRes {
+	res := make([]dto.InvitationRes, len(links))
+	for i := range links {
+		res[i] = toInvitationRes(&links[i])
+	}
+	return res
+}
+
+func toCoachingRes(link *model.CoachingLink) dto.CoachingRes {
+	return dto.CoachingRes{
+		CoachID:    link.CoachID,
+		ClientID:   link.ClientID,
+		Access:     link.Access,
+		AcceptedAt: link.AcceptedAt,
+	}
+}
+
+func toCoachingResList(links []model.CoachingLink) []dto.CoachingRes {
+	res := make([]dto.CoachingRes, len(links))
+	for i := range links {
+		res[i] = toCoachingRes(&links[i])
+	}
+	return res
+}
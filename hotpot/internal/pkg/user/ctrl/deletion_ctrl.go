@@ -0,0 +1,50 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/user/dto"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/svc"
+)
+
+func (c *UserCtrl) DeleteMe(ctx *fiber.Ctx) error {
+	req, err := c.userSvc.RequestDeletion(ctx.Context(), mw.UserID(ctx))
+	if err != nil {
+		return c.deletionError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Accepted, toDeletionRes(req), 0, "")
+}
+
+func (c *UserCtrl) Deletion(ctx *fiber.Ctx) error {
+	req, err := c.userSvc.Deletion(ctx.Context(), mw.UserID(ctx))
+	if err != nil {
+		return c.deletionError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toDeletionRes(req), 0, "")
+}
+
+func (c *UserCtrl) CancelDeletion(ctx *fiber.Ctx) error {
+	if err := c.userSvc.CancelDeletion(ctx.Context(), mw.UserID(ctx)); err != nil {
+		return c.deletionError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+// deletionError maps account deletion errors to responses.
+func (c *UserCtrl) deletionError(ctx *fiber.Ctx, err error) error {
+	if errors.Is(err, svc.ErrNoDeletionScheduled) {
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	}
+	c.logger.Error("account deletion request failed", "error", err)
+	return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+}
+
+func toDeletionRes(req *model.DeletionRequest) dto.DeletionRes {
+	return dto.DeletionRes{
+		RequestedAt:  req.RequestedAt,
+		ScheduledFor: req.ScheduledFor,
+	}
+}
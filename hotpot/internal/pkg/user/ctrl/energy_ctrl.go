@@ -0,0 +1,46 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/user/dto"
+	"hotpot/internal/pkg/user/svc"
+)
+
+func (c *UserCtrl) Energy(ctx *fiber.Ctx) error {
+	var q dto.EnergyQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	needs, err := c.userSvc.EnergyNeeds(ctx.Context(), mw.UserID(ctx), svc.Formula(q.Formula))
+	if err != nil {
+		var incomplete *svc.IncompleteProfileError
+		switch {
+		case errors.As(err, &incomplete), errors.Is(err, svc.ErrFormulaUnavailable):
+			return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+		default:
+			return c.profileError(ctx, err)
+		}
+	}
+
+	res := dto.EnergyRes{
+		Sex:            needs.Input.Sex,
+		Age:            needs.Input.Age,
+		WeightKg:       needs.Input.WeightKg,
+		HeightCm:       needs.Input.HeightCm,
+		BodyFatPct:     needs.Input.BodyFatPct,
+		ActivityLevel:  needs.Input.Activity,
+		ActivityFactor: needs.Input.Activity.Factor(),
+		BMR:            make(map[string]float64, len(needs.BMR)),
+		Formula:        string(needs.Formula),
+		BMRKcal:        needs.BMRKcal,
+		TDEEKcal:       needs.TDEEKcal,
+	}
+	for f, v := range needs.BMR {
+		res.BMR[string(f)] = v
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
@@ -0,0 +1,63 @@
+package ctrl
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/user/dto"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/svc"
+)
+
+func (c *UserCtrl) StartExport(ctx *fiber.Ctx) error {
+	job, err := c.userSvc.StartExport(ctx.Context(), mw.UserID(ctx))
+	if err != nil {
+		return c.exportError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Accepted, toExportRes(job), 0, "")
+}
+
+// DownloadExport sends the archive once the job is done, and the job status until then.
+func (c *UserCtrl) DownloadExport(ctx *fiber.Ctx) error {
+	job, err := c.userSvc.Export(ctx.Context(), mw.UserID(ctx), ctx.Params("id"))
+	if err != nil {
+		return c.exportError(ctx, err)
+	}
+
+	switch job.Status {
+	case model.ExportDone:
+		ctx.Set(fiber.HeaderContentType, "application/zip")
+		ctx.Set(fiber.HeaderContentDisposition,
+			fmt.Sprintf(`attachment; filename="hotpot-export-%s.zip"`, job.CreatedAt.Format("2006-01-02")))
+		return ctx.Send(job.Archive)
+	case model.ExportFailed:
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Export failed, please start a new one")
+	default:
+		return http.NewResponse(ctx, http.Accepted, toExportRes(job), 0, "")
+	}
+}
+
+// exportError maps data export errors to responses.
+func (c *UserCtrl) exportError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrExportNotFound):
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	case errors.Is(err, svc.ErrExportInProgress):
+		return http.NewResponse(ctx, http.Conflict, nil, http.CodeAlreadyExists, err.Error())
+	default:
+		c.logger.Error("export request failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
+
+func toExportRes(job *model.ExportJob) dto.ExportRes {
+	return dto.ExportRes{
+		ID:          job.ID,
+		Status:      string(job.Status),
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+	}
+}
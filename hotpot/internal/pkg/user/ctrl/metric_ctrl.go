@@ -0,0 +1,74 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/user/dto"
+	"hotpot/internal/pkg/user/svc"
+)
+
+func (c *UserCtrl) CreateMeasurement(ctx *fiber.Ctx) error {
+	var req dto.CreateMeasurementReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	m, err := c.userSvc.RecordMeasurement(ctx.Context(), mw.UserID(ctx), req.MeasuredAt, req.WeightKg, req.BodyFatPct, req.WaistCm)
+	if err != nil {
+		return c.measurementError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.Created, toMeasurementRes(svc.MetricPoint{Measurement: *m}), 0, "")
+}
+
+func (c *UserCtrl) ListMeasurements(ctx *fiber.Ctx) error {
+	var q dto.MeasurementQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	points, err := c.userSvc.Measurements(ctx.Context(), mw.UserID(ctx), q.From, q.To)
+	if err != nil {
+		return c.measurementError(ctx, err)
+	}
+
+	res := make([]dto.MeasurementRes, len(points))
+	for i, p := range points {
+		res[i] = toMeasurementRes(p)
+	}
+	return http.NewResponse(ctx, http.OK, res, 0, "")
+}
+
+func (c *UserCtrl) DeleteMeasurement(ctx *fiber.Ctx) error {
+	if err := c.userSvc.DeleteMeasurement(ctx.Context(), mw.UserID(ctx), ctx.Params("id")); err != nil {
+		return c.measurementError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, true, 0, "")
+}
+
+// measurementError maps body metrics errors to responses.
+func (c *UserCtrl) measurementError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrMeasurementNotFound):
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	case errors.Is(err, svc.ErrEmptyMeasurement), errors.Is(err, svc.ErrFutureMeasurement):
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	default:
+		c.logger.Error("measurement request failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
+
+func toMeasurementRes(p svc.MetricPoint) dto.MeasurementRes {
+	return dto.MeasurementRes{
+		ID:           p.ID,
+		MeasuredAt:   p.MeasuredAt,
+		WeightKg:     p.WeightKg,
+		BodyFatPct:   p.BodyFatPct,
+		WaistCm:      p.WaistCm,
+		WeightTrend:  p.WeightTrend,
+		BodyFatTrend: p.BodyFatTrend,
+		WaistTrend:   p.WaistTrend,
+	}
+}
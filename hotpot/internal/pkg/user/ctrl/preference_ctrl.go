@@ -0,0 +1,51 @@
+package ctrl
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/user/dto"
+	"hotpot/internal/pkg/user/model"
+)
+
+func (c *UserCtrl) Preferences(ctx *fiber.Ctx) error {
+	prefs, err := c.userSvc.Preferences(ctx.Context(), mw.UserID(ctx))
+	if err != nil {
+		return c.profileError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toPreferencesRes(prefs), 0, "")
+}
+
+func (c *UserCtrl) SetPreferences(ctx *fiber.Ctx) error {
+	var req dto.PreferencesReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	prefs, err := c.userSvc.SetPreferences(ctx.Context(), mw.UserID(ctx), req.Restrictions, req.Allergens, req.Dislikes)
+	if err != nil {
+		return c.profileError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toPreferencesRes(prefs), 0, "")
+}
+
+func toPreferencesRes(p *model.Preferences) dto.PreferencesRes {
+	res := dto.PreferencesRes{
+		Restrictions: p.Restrictions,
+		Allergens:    p.Allergens,
+		Dislikes:     p.Dislikes,
+	}
+	if res.Restrictions == nil {
+		res.Restrictions = []model.Restriction{}
+	}
+	if res.Allergens == nil {
+		res.Allergens = []model.Allergen{}
+	}
+	if res.Dislikes == nil {
+		res.Dislikes = []string{}
+	}
+	if !p.UpdatedAt.IsZero() {
+		res.UpdatedAt = &p.UpdatedAt
+	}
+	return res
+}
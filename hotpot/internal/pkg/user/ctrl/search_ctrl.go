@@ -0,0 +1,73 @@
+package ctrl
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/utils/servers/http"
+	authmodel "hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/user/dto"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/svc"
+	"strings"
+)
+
+const (
+	// defaultUserLimit is the page size when the query does not set one.
+	defaultUserLimit = 20
+	// defaultUserSort lists the newest users first.
+	defaultUserSort = "-created_at"
+)
+
+func (c *UserCtrl) ListUsers(ctx *fiber.Ctx) error {
+	var q dto.UserSearchQuery
+	if err := http.ParseQuery(ctx, &q); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultUserLimit
+	}
+	if q.Sort == "" {
+		q.Sort = defaultUserSort
+	}
+	sortBy, descending := strings.CutPrefix(q.Sort, "-")
+
+	users, next, err := c.userSvc.SearchUsers(mw.RequestContext(ctx), svc.UserQuery{
+		Email:       q.Email,
+		Role:        authmodel.Role(q.Role),
+		Status:      model.UserStatus(q.Status),
+		Name:        q.Q,
+		CreatedFrom: q.CreatedFrom,
+		CreatedTo:   q.CreatedTo,
+		Sort:        model.UserSort(sortBy),
+		Descending:  descending,
+		Cursor:      q.Cursor,
+		Limit:       q.Limit,
+	})
+	if err != nil {
+		if errors.Is(err, svc.ErrInvalidCursor) {
+			return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+		}
+		c.logger.Error("search users failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+
+	res := make([]dto.UserSummaryRes, len(users))
+	for i, u := range users {
+		res[i] = dto.UserSummaryRes{
+			ID:                   u.ID,
+			Email:                u.Email,
+			EmailVerified:        u.EmailVerified,
+			Roles:                u.Roles,
+			DisplayName:          u.DisplayName,
+			Status:               u.Status,
+			CreatedAt:            u.CreatedAt,
+			DeletionScheduledFor: u.DeletionScheduledFor,
+		}
+	}
+	return http.NewPagedResponse(ctx, http.OK, res, http.Paging{
+		Limit:      q.Limit,
+		NextCursor: next,
+		HasMore:    next != "",
+	})
+}
@@ -1,12 +1,20 @@
 package ctrl
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"hotpot/internal/core/utils/servers/http"
+	"hotpot/internal/pkg/auth/mw"
+	"hotpot/internal/pkg/user/dto"
+	"hotpot/internal/pkg/user/model"
 	"hotpot/internal/pkg/user/svc"
 	"log/slog"
+	"time"
 )
 
+// dateLayout is the format of calendar dates in requests and responses.
+const dateLayout = "2006-01-02"
+
 type UserCtrl struct {
 	logger  *slog.Logger
 	userSvc *svc.UserSvc
@@ -26,3 +34,98 @@ func (c *UserCtrl) Ping(ctx *fiber.Ctx) error {
 	}
 	return http.NewResponse(ctx, http.OK, res, 0, "")
 }
+
+func (c *UserCtrl) Me(ctx *fiber.Ctx) error {
+	return c.profile(ctx, mw.UserID(ctx))
+}
+
+func (c *UserCtrl) Get(ctx *fiber.Ctx) error {
+	return c.profile(ctx, ctx.Params("id"))
+}
+
+func (c *UserCtrl) UpdateMe(ctx *fiber.Ctx) error {
+	var req dto.UpdateProfileReq
+	if err := http.ParseBody(ctx, &req); err != nil {
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	}
+
+	update := svc.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		Sex:         req.Sex,
+		HeightCm:    req.HeightCm,
+		Units:       req.Units,
+		Activity:    req.Activity,
+		Timezone:    req.Timezone,
+		AvatarURL:   req.AvatarURL,
+	}
+	if req.BirthDate != nil {
+		// The format was already checked by the validator.
+		b, _ := time.Parse(dateLayout, *req.BirthDate)
+		update.BirthDate = &b
+	}
+
+	profile, err := c.userSvc.UpdateProfile(ctx.Context(), mw.UserID(ctx), update)
+	if err != nil {
+		return c.profileError(ctx, err)
+	}
+	return c.sendProfile(ctx, profile)
+}
+
+func (c *UserCtrl) profile(ctx *fiber.Ctx, userID string) error {
+	profile, err := c.userSvc.Profile(ctx.Context(), userID)
+	if err != nil {
+		return c.profileError(ctx, err)
+	}
+	return c.sendProfile(ctx, profile)
+}
+
+// sendProfile responds with the profile, signing links to its picture.
+func (c *UserCtrl) sendProfile(ctx *fiber.Ctx, profile *model.Profile) error {
+	avatars, err := c.userSvc.AvatarURLs(ctx.Context(), profile)
+	if err != nil {
+		return c.profileError(ctx, err)
+	}
+	return http.NewResponse(ctx, http.OK, toProfileRes(profile, avatars), 0, "")
+}
+
+// profileError maps profile errors to responses.
+func (c *UserCtrl) profileError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, svc.ErrUserNotFound):
+		return http.NewResponse(ctx, http.NotFound, nil, http.CodeNotFound, err.Error())
+	case errors.Is(err, svc.ErrInvalidTimezone), errors.Is(err, svc.ErrInvalidBirthDate):
+		return http.NewResponse(ctx, http.BadRequest, nil, http.CodeValidationError, err.Error())
+	case errors.Is(err, svc.ErrAvatarTooLarge):
+		return http.NewResponse(ctx, http.PayloadTooLarge, nil, http.CodeValidationError, err.Error())
+	case errors.Is(err, svc.ErrUnsupportedImage):
+		return http.NewResponse(ctx, http.UnsupportedMediaType, nil, http.CodeValidationError, err.Error())
+	default:
+		c.logger.Error("profile request failed", "error", err)
+		return http.NewResponse(ctx, http.InternalServerError, nil, http.CodeInternalError, "Something went wrong!")
+	}
+}
+
+// toProfileRes converts a profile, using the 256 px variant of an uploaded
+// picture as its avatar URL.
+func toProfileRes(p *model.Profile, avatars map[string]string) dto.ProfileRes {
+	res := dto.ProfileRes{
+		UserID:      p.UserID,
+		DisplayName: p.DisplayName,
+		Sex:         p.Sex,
+		HeightCm:    p.HeightCm,
+		Units:       p.Units,
+		Activity:    p.Activity,
+		Timezone:    p.Timezone,
+		AvatarURL:   p.AvatarURL,
+		Avatars:     avatars,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	if u, ok := avatars["256"]; ok {
+		res.AvatarURL = u
+	}
+	if p.BirthDate != nil {
+		b := p.BirthDate.Format(dateLayout)
+		res.BirthDate = &b
+	}
+	return res
+}
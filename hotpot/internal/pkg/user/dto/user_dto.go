@@ -0,0 +1,164 @@
+package dto
+
+import (
+	authmodel "hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/user/model"
+	"time"
+)
+
+// UpdateProfileReq is a partial update; omitted fields keep their value.
+type UpdateProfileReq struct {
+	DisplayName *string `json:"display_name" validate:"omitempty,max=50"`
+	// BirthDate is formatted as YYYY-MM-DD.
+	BirthDate *string              `json:"birth_date" validate:"omitempty,datetime=2006-01-02"`
+	Sex       *model.Sex           `json:"sex" validate:"omitempty,oneof=male female"`
+	HeightCm  *float64             `json:"height_cm" validate:"omitempty,gte=50,lte=272"`
+	Units     *model.Units         `json:"units" validate:"omitempty,oneof=metric imperial"`
+	Activity  *model.ActivityLevel `json:"activity_level" validate:"omitempty,oneof=sedentary light moderate active very_active"`
+	Timezone  *string              `json:"timezone" validate:"omitempty,max=64"`
+	// AvatarURL may be set to an empty string to remove the avatar.
+	AvatarURL *string `json:"avatar_url" validate:"omitempty,max=2048,url|eq="`
+}
+
+type ProfileRes struct {
+	UserID      string              `json:"user_id"`
+	DisplayName string              `json:"display_name"`
+	BirthDate   *string             `json:"birth_date"`
+	Sex         model.Sex           `json:"sex"`
+	HeightCm    *float64            `json:"height_cm"`
+	Units       model.Units         `json:"units"`
+	Activity    model.ActivityLevel `json:"activity_level"`
+	Timezone    string              `json:"timezone"`
+	AvatarURL   string              `json:"avatar_url"`
+	// Avatars maps edge lengths in pixels to links to an uploaded picture.
+	// The links expire.
+	Avatars   map[string]string `json:"avatars,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type CreateMeasurementReq struct {
+	// MeasuredAt defaults to now.
+	MeasuredAt time.Time `json:"measured_at"`
+	WeightKg   *float64  `json:"weight_kg" validate:"omitempty,gt=20,lt=500"`
+	BodyFatPct *float64  `json:"body_fat_pct" validate:"omitempty,gt=1,lt=75"`
+	WaistCm    *float64  `json:"waist_cm" validate:"omitempty,gt=30,lt=300"`
+}
+
+type MeasurementQuery struct {
+	// From and To are RFC 3339 timestamps; To is exclusive.
+	From time.Time `query:"from"`
+	To   time.Time `query:"to"`
+}
+
+type MeasurementRes struct {
+	ID           string    `json:"id"`
+	MeasuredAt   time.Time `json:"measured_at"`
+	WeightKg     *float64  `json:"weight_kg"`
+	BodyFatPct   *float64  `json:"body_fat_pct"`
+	WaistCm      *float64  `json:"waist_cm"`
+	WeightTrend  *float64  `json:"weight_trend,omitempty"`
+	BodyFatTrend *float64  `json:"body_fat_trend,omitempty"`
+	WaistTrend   *float64  `json:"waist_trend,omitempty"`
+}
+
+type EnergyQuery struct {
+	Formula string `query:"formula" validate:"omitempty,oneof=mifflin_st_jeor harris_benedict katch_mcardle"`
+}
+
+type EnergyRes struct {
+	Sex            model.Sex           `json:"sex"`
+	Age            int                 `json:"age"`
+	WeightKg       float64             `json:"weight_kg"`
+	HeightCm       float64             `json:"height_cm"`
+	BodyFatPct     *float64            `json:"body_fat_pct"`
+	ActivityLevel  model.ActivityLevel `json:"activity_level"`
+	ActivityFactor float64             `json:"activity_factor"`
+	// BMR maps every applicable formula to its result in kcal.
+	BMR      map[string]float64 `json:"bmr"`
+	Formula  string             `json:"formula"`
+	BMRKcal  float64            `json:"bmr_kcal"`
+	TDEEKcal float64            `json:"tdee_kcal"`
+}
+
+type ExportRes struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+}
+
+type DeletionRes struct {
+	RequestedAt  time.Time `json:"requested_at"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
+type PreferencesReq struct {
+	Restrictions []model.Restriction `json:"restrictions" validate:"max=5,dive,oneof=vegetarian vegan halal kosher gluten_free"`
+	Allergens    []model.Allergen    `json:"allergens" validate:"max=14,dive,oneof=gluten crustaceans eggs fish peanuts soybeans milk nuts celery mustard sesame sulphites lupin molluscs"`
+	Dislikes     []string            `json:"dislikes" validate:"max=50,dive,max=50"`
+}
+
+type PreferencesRes struct {
+	Restrictions []model.Restriction `json:"restrictions"`
+	Allergens    []model.Allergen    `json:"allergens"`
+	Dislikes     []string            `json:"dislikes"`
+	UpdatedAt    *time.Time          `json:"updated_at"`
+}
+
+type InviteClientReq struct {
+	// Email restricts the invitation to one account. Without it, anyone
+	// holding the code can accept it.
+	Email  string               `json:"email" validate:"omitempty,email,max=254"`
+	Access model.CoachingAccess `json:"access" validate:"required,oneof=read write"`
+}
+
+type InvitationRes struct {
+	ID        string               `json:"id"`
+	CoachID   string               `json:"coach_id"`
+	Email     string               `json:"email,omitempty"`
+	Access    model.CoachingAccess `json:"access"`
+	CreatedAt time.Time            `json:"created_at"`
+	ExpiresAt time.Time            `json:"expires_at"`
+	// Code is only returned when the invitation is created.
+	Code string `json:"code,omitempty"`
+}
+
+// AcceptInvitationReq accepts either an invitation sent to the user's email or a code.
+type AcceptInvitationReq struct {
+	InvitationID string `json:"invitation_id" validate:"required_without=Code,omitempty,uuid"`
+	Code         string `json:"code" validate:"required_without=InvitationID,omitempty,max=20"`
+}
+
+type CoachingRes struct {
+	CoachID    string               `json:"coach_id"`
+	ClientID   string               `json:"client_id"`
+	Access     model.CoachingAccess `json:"access"`
+	AcceptedAt *time.Time           `json:"accepted_at"`
+}
+
+type UserSearchQuery struct {
+	Email  string `query:"email" validate:"omitempty,max=254"`
+	Role   string `query:"role" validate:"omitempty,oneof=user dietitian admin"`
+	Status string `query:"status" validate:"omitempty,oneof=active unverified pending_deletion"`
+	// Q searches display names.
+	Q string `query:"q" validate:"omitempty,max=100"`
+	// CreatedFrom and CreatedTo are RFC 3339 timestamps; CreatedTo is exclusive.
+	CreatedFrom time.Time `query:"created_from"`
+	CreatedTo   time.Time `query:"created_to"`
+	// Sort is a field name, prefixed with "-" for descending order.
+	Sort   string `query:"sort" validate:"omitempty,oneof=created_at -created_at email -email name -name"`
+	Cursor string `query:"cursor" validate:"omitempty,max=1024"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type UserSummaryRes struct {
+	ID                   string           `json:"id"`
+	Email                string           `json:"email"`
+	EmailVerified        bool             `json:"email_verified"`
+	Roles                []authmodel.Role `json:"roles"`
+	DisplayName          string           `json:"display_name"`
+	Status               model.UserStatus `json:"status"`
+	CreatedAt            time.Time        `json:"created_at"`
+	DeletionScheduledFor *time.Time       `json:"deletion_scheduled_for"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// CoachingAccess is what a coach may do with a client's data.
+type CoachingAccess string
+
+const (
+	AccessRead  CoachingAccess = "read"
+	AccessWrite CoachingAccess = "write" // Includes read.
+)
+
+// CoachingStatus is the state of a coach-client relationship.
+type CoachingStatus string
+
+const (
+	CoachingPending  CoachingStatus = "pending"  // Invitation sent, not yet answered.
+	CoachingActive   CoachingStatus = "active"   // Accepted by the client.
+	CoachingDeclined CoachingStatus = "declined" // Declined by the client or withdrawn by the coach.
+	CoachingRevoked  CoachingStatus = "revoked"  // Ended by either side after being active.
+)
+
+// CoachingLink is a relationship between a dietitian and a client. It starts
+// as an invitation, either addressed to an email or redeemable by anyone
+// holding its code, and grants access once the client accepts it.
+type CoachingLink struct {
+	ID      string
+	CoachID string
+	// ClientID is set when the invitation is accepted.
+	ClientID string
+	// InviteEmail restricts who may accept the invitation, if set.
+	InviteEmail string
+	// CodeHash is the SHA-256 hash of the invitation code.
+	CodeHash  string
+	Access    CoachingAccess
+	Status    CoachingStatus
+	CreatedAt time.Time
+	// ExpiresAt is when a pending invitation can no longer be accepted.
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
+	EndedAt    *time.Time
+}
+
+// Grants reports whether the link is active and allows the access.
+func (l *CoachingLink) Grants(access CoachingAccess) bool {
+	if l.Status != CoachingActive {
+		return false
+	}
+	return access == AccessRead || l.Access == AccessWrite
+}
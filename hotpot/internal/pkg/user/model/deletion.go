@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// DeletionRequest schedules the erasure of a user's account and all their
+// data. Until ScheduledFor the user can cancel it.
+type DeletionRequest struct {
+	UserID       string
+	RequestedAt  time.Time
+	ScheduledFor time.Time
+}
@@ -0,0 +1,19 @@
+package model
+
+// UserStatus is the lifecycle state of a user, shown in the admin directory.
+type UserStatus string
+
+const (
+	StatusActive          UserStatus = "active"
+	StatusUnverified      UserStatus = "unverified" // Email address not verified yet.
+	StatusPendingDeletion UserStatus = "pending_deletion"
+)
+
+// UserSort orders the admin directory. A leading "-" sorts descending.
+type UserSort string
+
+const (
+	SortCreatedAt UserSort = "created_at"
+	SortEmail     UserSort = "email"
+	SortName      UserSort = "name"
+)
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// ExportStatus is the state of a data export job.
+type ExportStatus string
+
+const (
+	ExportPending ExportStatus = "pending"
+	ExportDone    ExportStatus = "done"
+	ExportFailed  ExportStatus = "failed"
+)
+
+// ExportJob is an asynchronous export of all data stored about a user.
+type ExportJob struct {
+	ID     string
+	UserID string
+	Status ExportStatus
+	// Archive is the ZIP file, set once the job is done.
+	Archive     []byte
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	// ExpiresAt is when the archive is deleted.
+	ExpiresAt time.Time
+}
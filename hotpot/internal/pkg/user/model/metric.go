@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Measurement is one entry of a user's body metrics history. Any of the
+// values may be missing, e.g. when only the weight was taken that day.
+type Measurement struct {
+	ID         string
+	UserID     string
+	MeasuredAt time.Time
+	WeightKg   *float64
+	BodyFatPct *float64
+	WaistCm    *float64
+	CreatedAt  time.Time
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Restriction is a diet the user follows. A food satisfies it only when it is
+// labelled with it.
+type Restriction string
+
+const (
+	RestrictionVegetarian Restriction = "vegetarian"
+	RestrictionVegan      Restriction = "vegan"
+	RestrictionHalal      Restriction = "halal"
+	RestrictionKosher     Restriction = "kosher"
+	RestrictionGlutenFree Restriction = "gluten_free"
+)
+
+// Restrictions lists every known restriction.
+var Restrictions = []Restriction{
+	RestrictionVegetarian, RestrictionVegan, RestrictionHalal, RestrictionKosher, RestrictionGlutenFree,
+}
+
+// Allergen is one of the 14 allergens that must be declared on food in the
+// EU (Regulation 1169/2011, Annex II).
+type Allergen string
+
+const (
+	AllergenGluten      Allergen = "gluten" // Cereals containing gluten.
+	AllergenCrustaceans Allergen = "crustaceans"
+	AllergenEggs        Allergen = "eggs"
+	AllergenFish        Allergen = "fish"
+	AllergenPeanuts     Allergen = "peanuts"
+	AllergenSoybeans    Allergen = "soybeans"
+	AllergenMilk        Allergen = "milk"
+	AllergenNuts        Allergen = "nuts" // Tree nuts such as almonds and hazelnuts.
+	AllergenCelery      Allergen = "celery"
+	AllergenMustard     Allergen = "mustard"
+	AllergenSesame      Allergen = "sesame"
+	AllergenSulphites   Allergen = "sulphites"
+	AllergenLupin       Allergen = "lupin"
+	AllergenMolluscs    Allergen = "molluscs"
+)
+
+// Allergens lists every known allergen.
+var Allergens = []Allergen{
+	AllergenGluten, AllergenCrustaceans, AllergenEggs, AllergenFish, AllergenPeanuts,
+	AllergenSoybeans, AllergenMilk, AllergenNuts, AllergenCelery, AllergenMustard,
+	AllergenSesame, AllergenSulphites, AllergenLupin, AllergenMolluscs,
+}
+
+// Preferences are the foods a user cannot or does not want to eat.
+type Preferences struct {
+	UserID       string
+	Restrictions []Restriction
+	Allergens    []Allergen
+	// Dislikes are ingredient names, matched case-insensitively.
+	Dislikes  []string
+	UpdatedAt time.Time
+}
+
+// Food describes what other modules know about a food or dish when checking
+// it against preferences.
+type Food struct {
+	Name        string
+	Ingredients []string
+	Allergens   []Allergen
+	// Labels are the restrictions the food is known to satisfy.
+	Labels []Restriction
+}
+
+// ViolationKind tells which preference a food violates.
+type ViolationKind string
+
+const (
+	ViolationRestriction ViolationKind = "restriction"
+	ViolationAllergen    ViolationKind = "allergen"
+	ViolationDislike     ViolationKind = "dislike"
+)
+
+// Violation is a reason the user should not be offered a food.
+type Violation struct {
+	Kind  ViolationKind
+	Value string
+}
+
+// Violations returns every preference the food violates, allergens first.
+func (p *Preferences) Violations(food Food) []Violation {
+	var violations []Violation
+	for _, a := range p.Allergens {
+		if slices.Contains(food.Allergens, a) {
+			violations = append(violations, Violation{Kind: ViolationAllergen, Value: string(a)})
+		}
+	}
+	for _, r := range p.Restrictions {
+		if !satisfies(food.Labels, r) {
+			violations = append(violations, Violation{Kind: ViolationRestriction, Value: string(r)})
+		}
+	}
+	for _, d := range p.Dislikes {
+		if containsIngredient(food, d) {
+			violations = append(violations, Violation{Kind: ViolationDislike, Value: d})
+		}
+	}
+	return violations
+}
+
+// satisfies reports whether the labels satisfy the restriction. Vegan food
+// is vegetarian too.
+func satisfies(labels []Restriction, r Restriction) bool {
+	if slices.Contains(labels, r) {
+		return true
+	}
+	return r == RestrictionVegetarian && slices.Contains(labels, RestrictionVegan)
+}
+
+func containsIngredient(food Food, dislike string) bool {
+	dislike = strings.ToLower(dislike)
+	if strings.Contains(strings.ToLower(food.Name), dislike) {
+		return true
+	}
+	return slices.ContainsFunc(food.Ingredients, func(i string) bool {
+		return strings.Contains(strings.ToLower(i), dislike)
+	})
+}
@@ -0,0 +1,93 @@
+package model
+
+import "time"
+
+// Sex is the biological sex used by nutrition formulas.
+type Sex string
+
+const (
+	SexMale   Sex = "male"
+	SexFemale Sex = "female"
+)
+
+// Units is the measurement system the user wants to see values in.
+// Values are always stored in metric units.
+type Units string
+
+const (
+	UnitsMetric   Units = "metric"
+	UnitsImperial Units = "imperial"
+)
+
+// ActivityLevel describes how active the user is outside of sleep.
+type ActivityLevel string
+
+const (
+	ActivitySedentary  ActivityLevel = "sedentary"   // Desk job, little exercise.
+	ActivityLight      ActivityLevel = "light"       // Exercise 1-3 days a week.
+	ActivityModerate   ActivityLevel = "moderate"    // Exercise 3-5 days a week.
+	ActivityActive     ActivityLevel = "active"      // Hard exercise 6-7 days a week.
+	ActivityVeryActive ActivityLevel = "very_active" // Physical job or training twice a day.
+)
+
+// activityFactors are the multipliers of the basal metabolic rate that give
+// the total daily energy expenditure.
+var activityFactors = map[ActivityLevel]float64{
+	ActivitySedentary:  1.2,
+	ActivityLight:      1.375,
+	ActivityModerate:   1.55,
+	ActivityActive:     1.725,
+	ActivityVeryActive: 1.9,
+}
+
+// Factor returns the TDEE multiplier of the activity level, or 0 if it is unknown.
+func (a ActivityLevel) Factor() float64 {
+	return activityFactors[a]
+}
+
+// Profile holds the personal details of a user. Its ID is the auth account ID.
+type Profile struct {
+	UserID      string
+	DisplayName string
+	// BirthDate is a calendar date at midnight UTC.
+	BirthDate *time.Time
+	Sex       Sex
+	HeightCm  *float64
+	Units     Units
+	Activity  ActivityLevel
+	// Timezone is an IANA name such as "Europe/Berlin".
+	Timezone string
+	// AvatarURL is a picture hosted elsewhere. AvatarKeys holds the blob keys
+	// of an uploaded picture by edge length, and takes precedence.
+	AvatarURL  string
+	AvatarKeys map[int]string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// AvatarSizes are the edge lengths in pixels of the variants kept of an
+// uploaded avatar.
+var AvatarSizes = []int{64, 256, 512}
+
+// NewProfile returns the profile of a user who has not filled anything in yet.
+func NewProfile(userID string) *Profile {
+	return &Profile{
+		UserID:   userID,
+		Units:    UnitsMetric,
+		Activity: ActivitySedentary,
+		Timezone: "UTC",
+	}
+}
+
+// Age returns the age in full years at the given time, or 0 if the birth date is unknown.
+func (p *Profile) Age(at time.Time) int {
+	if p.BirthDate == nil {
+		return 0
+	}
+	b := *p.BirthDate
+	age := at.Year() - b.Year()
+	if at.Month() < b.Month() || at.Month() == b.Month() && at.Day() < b.Day() {
+		age--
+	}
+	return age
+}
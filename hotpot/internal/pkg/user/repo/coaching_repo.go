@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/user/model"
+	"sort"
+	"sync"
+)
+
+// CoachingRepo persists coach-client relationships and invitations.
+type CoachingRepo interface {
+	Create(ctx context.Context, link *model.CoachingLink) error
+	Update(ctx context.Context, link *model.CoachingLink) error
+	FindByID(ctx context.Context, id string) (*model.CoachingLink, error)
+	FindByCodeHash(ctx context.Context, hash string) (*model.CoachingLink, error)
+	// FindActive returns the active link between the coach and the client.
+	FindActive(ctx context.Context, coachID, clientID string) (*model.CoachingLink, error)
+	// ListByCoach and ListByClient return links in the given statuses, newest first.
+	ListByCoach(ctx context.Context, coachID string, statuses ...model.CoachingStatus) ([]model.CoachingLink, error)
+	ListByClient(ctx context.Context, clientID string, statuses ...model.CoachingStatus) ([]model.CoachingLink, error)
+	// ListPendingByEmail returns the pending invitations addressed to the email.
+	ListPendingByEmail(ctx context.Context, email string) ([]model.CoachingLink, error)
+	// DeleteByUser removes every link the user is coach or client of.
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// MemoryCoachingRepo is an in-memory CoachingRepo.
+type MemoryCoachingRepo struct {
+	mu   sync.RWMutex
+	byID map[string]model.CoachingLink
+}
+
+func NewMemoryCoachingRepo() *MemoryCoachingRepo {
+	return &MemoryCoachingRepo{
+		byID: make(map[string]model.CoachingLink),
+	}
+}
+
+func (r *MemoryCoachingRepo) Create(_ context.Context, link *model.CoachingLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[link.ID] = *link
+	return nil
+}
+
+func (r *MemoryCoachingRepo) Update(_ context.Context, link *model.CoachingLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[link.ID]; !ok {
+		return ErrNotFound
+	}
+	r.byID[link.ID] = *link
+	return nil
+}
+
+func (r *MemoryCoachingRepo) FindByID(_ context.Context, id string) (*model.CoachingLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	link, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &link, nil
+}
+
+func (r *MemoryCoachingRepo) FindByCodeHash(_ context.Context, hash string) (*model.CoachingLink, error) {
+	return r.find(func(l *model.CoachingLink) bool { return l.CodeHash == hash })
+}
+
+func (r *MemoryCoachingRepo) FindActive(_ context.Context, coachID, clientID string) (*model.CoachingLink, error) {
+	return r.find(func(l *model.CoachingLink) bool {
+		return l.CoachID == coachID && l.ClientID == clientID && l.Status == model.CoachingActive
+	})
+}
+
+func (r *MemoryCoachingRepo) ListByCoach(_ context.Context, coachID string, statuses ...model.CoachingStatus) ([]model.CoachingLink, error) {
+	return r.list(func(l *model.CoachingLink) bool {
+		return l.CoachID == coachID && hasStatus(l, statuses)
+	}), nil
+}
+
+func (r *MemoryCoachingRepo) ListByClient(_ context.Context, clientID string, statuses ...model.CoachingStatus) ([]model.CoachingLink, error) {
+	return r.list(func(l *model.CoachingLink) bool {
+		return l.ClientID == clientID && hasStatus(l, statuses)
+	}), nil
+}
+
+func (r *MemoryCoachingRepo) ListPendingByEmail(_ context.Context, email string) ([]model.CoachingLink, error) {
+	return r.list(func(l *model.CoachingLink) bool {
+		return l.InviteEmail == email && l.Status == model.CoachingPending
+	}), nil
+}
+
+func (r *MemoryCoachingRepo) DeleteByUser(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, link := range r.byID {
+		if link.CoachID == userID || link.ClientID == userID {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryCoachingRepo) find(match func(*model.CoachingLink) bool) (*model.CoachingLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, link := range r.byID {
+		if match(&link) {
+			return &link, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryCoachingRepo) list(match func(*model.CoachingLink) bool) []model.CoachingLink {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var links []model.CoachingLink
+	for _, link := range r.byID {
+		if match(&link) {
+			links = append(links, link)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].CreatedAt.After(links[j].CreatedAt)
+	})
+	return links
+}
+
+func hasStatus(link *model.CoachingLink, statuses []model.CoachingStatus) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if link.Status == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/user/model"
+	"sync"
+	"time"
+)
+
+// DeletionRepo persists scheduled account deletions.
+type DeletionRepo interface {
+	// Save creates or replaces the user's request.
+	Save(ctx context.Context, req *model.DeletionRequest) error
+	FindByUserID(ctx context.Context, userID string) (*model.DeletionRequest, error)
+	// ListDue returns the requests scheduled at or before the given time.
+	ListDue(ctx context.Context, at time.Time) ([]model.DeletionRequest, error)
+	Delete(ctx context.Context, userID string) error
+}
+
+// MemoryDeletionRepo is an in-memory DeletionRepo.
+type MemoryDeletionRepo struct {
+	mu       sync.RWMutex
+	byUserID map[string]model.DeletionRequest
+}
+
+func NewMemoryDeletionRepo() *MemoryDeletionRepo {
+	return &MemoryDeletionRepo{
+		byUserID: make(map[string]model.DeletionRequest),
+	}
+}
+
+func (r *MemoryDeletionRepo) Save(_ context.Context, req *model.DeletionRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byUserID[req.UserID] = *req
+	return nil
+}
+
+func (r *MemoryDeletionRepo) FindByUserID(_ context.Context, userID string) (*model.DeletionRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	req, ok := r.byUserID[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &req, nil
+}
+
+func (r *MemoryDeletionRepo) ListDue(_ context.Context, at time.Time) ([]model.DeletionRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var due []model.DeletionRequest
+	for _, req := range r.byUserID {
+		if !req.ScheduledFor.After(at) {
+			due = append(due, req)
+		}
+	}
+	return due, nil
+}
+
+func (r *MemoryDeletionRepo) Delete(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUserID[userID]; !ok {
+		return ErrNotFound
+	}
+	delete(r.byUserID, userID)
+	return nil
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/user/model"
+	"sync"
+	"time"
+)
+
+// ExportRepo persists data export jobs and their archives.
+type ExportRepo interface {
+	Create(ctx context.Context, job *model.ExportJob) error
+	Update(ctx context.Context, job *model.ExportJob) error
+	FindByID(ctx context.Context, id string) (*model.ExportJob, error)
+	// FindPending returns the user's unfinished job, if any.
+	FindPending(ctx context.Context, userID string) (*model.ExportJob, error)
+	// DeleteExpired removes the jobs that expired before the given time.
+	DeleteExpired(ctx context.Context, before time.Time) error
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// MemoryExportRepo is an in-memory ExportRepo.
+type MemoryExportRepo struct {
+	mu   sync.RWMutex
+	byID map[string]model.ExportJob
+}
+
+func NewMemoryExportRepo() *MemoryExportRepo {
+	return &MemoryExportRepo{
+		byID: make(map[string]model.ExportJob),
+	}
+}
+
+func (r *MemoryExportRepo) Create(_ context.Context, job *model.ExportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[job.ID] = *job
+	return nil
+}
+
+func (r *MemoryExportRepo) Update(_ context.Context, job *model.ExportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[job.ID]; !ok {
+		return ErrNotFound
+	}
+	r.byID[job.ID] = *job
+	return nil
+}
+
+func (r *MemoryExportRepo) FindByID(_ context.Context, id string) (*model.ExportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &job, nil
+}
+
+func (r *MemoryExportRepo) FindPending(_ context.Context, userID string) (*model.ExportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, job := range r.byID {
+		if job.UserID == userID && job.Status == model.ExportPending {
+			return &job, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryExportRepo) DeleteExpired(_ context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, job := range r.byID {
+		if job.ExpiresAt.Before(before) {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryExportRepo) DeleteByUser(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, job := range r.byID {
+		if job.UserID == userID {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
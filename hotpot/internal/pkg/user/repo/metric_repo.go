@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/user/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MeasurementRepo persists body metrics.
+type MeasurementRepo interface {
+	Create(ctx context.Context, m *model.Measurement) error
+	FindByID(ctx context.Context, id string) (*model.Measurement, error)
+	// ListByUser returns the user's measurements taken before the given time,
+	// oldest first. A zero before returns all of them.
+	ListByUser(ctx context.Context, userID string, before time.Time) ([]model.Measurement, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// MemoryMeasurementRepo is an in-memory MeasurementRepo.
+type MemoryMeasurementRepo struct {
+	mu   sync.RWMutex
+	byID map[string]model.Measurement
+}
+
+func NewMemoryMeasurementRepo() *MemoryMeasurementRepo {
+	return &MemoryMeasurementRepo{
+		byID: make(map[string]model.Measurement),
+	}
+}
+
+func (r *MemoryMeasurementRepo) Create(_ context.Context, m *model.Measurement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byID[m.ID] = *m
+	return nil
+}
+
+func (r *MemoryMeasurementRepo) FindByID(_ context.Context, id string) (*model.Measurement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &m, nil
+}
+
+func (r *MemoryMeasurementRepo) ListByUser(_ context.Context, userID string, before time.Time) ([]model.Measurement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var list []model.Measurement
+	for _, m := range r.byID {
+		if m.UserID == userID && (before.IsZero() || m.MeasuredAt.Before(before)) {
+			list = append(list, m)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].MeasuredAt.Before(list[j].MeasuredAt)
+	})
+	return list, nil
+}
+
+func (r *MemoryMeasurementRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.byID, id)
+	return nil
+}
+
+func (r *MemoryMeasurementRepo) DeleteByUser(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, m := range r.byID {
+		if m.UserID == userID {
+			delete(r.byID, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"hotpot/internal/pkg/user/model"
+	"sync"
+)
+
+// PreferenceRepo persists dietary preferences.
+type PreferenceRepo interface {
+	FindByUserID(ctx context.Context, userID string) (*model.Preferences, error)
+	// Save creates or replaces the preferences.
+	Save(ctx context.Context, prefs *model.Preferences) error
+	Delete(ctx context.Context, userID string) error
+}
+
+// MemoryPreferenceRepo is an in-memory PreferenceRepo.
+type MemoryPreferenceRepo struct {
+	mu       sync.RWMutex
+	byUserID map[string]model.Preferences
+}
+
+func NewMemoryPreferenceRepo() *MemoryPreferenceRepo {
+	return &MemoryPreferenceRepo{
+		byUserID: make(map[string]model.Preferences),
+	}
+}
+
+func (r *MemoryPreferenceRepo) FindByUserID(_ context.Context, userID string) (*model.Preferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs, ok := r.byUserID[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &prefs, nil
+}
+
+func (r *MemoryPreferenceRepo) Save(_ context.Context, prefs *model.Preferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byUserID[prefs.UserID] = *prefs
+	return nil
+}
+
+func (r *MemoryPreferenceRepo) Delete(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.byUserID, userID)
+	return nil
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/user/model"
+	"sync"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// Store groups the repositories used by the user module.
+type Store struct {
+	Profiles     ProfileRepo
+	Measurements MeasurementRepo
+	Exports      ExportRepo
+	Deletions    DeletionRepo
+	Preferences  PreferenceRepo
+	Coaching     CoachingRepo
+}
+
+// NewMemoryStore returns a Store backed entirely by in-memory repositories.
+func NewMemoryStore() *Store {
+	return &Store{
+		Profiles:     NewMemoryProfileRepo(),
+		Measurements: NewMemoryMeasurementRepo(),
+		Exports:      NewMemoryExportRepo(),
+		Deletions:    NewMemoryDeletionRepo(),
+		Preferences:  NewMemoryPreferenceRepo(),
+		Coaching:     NewMemoryCoachingRepo(),
+	}
+}
+
+// ProfileRepo persists user profiles.
+type ProfileRepo interface {
+	FindByUserID(ctx context.Context, userID string) (*model.Profile, error)
+	// Save creates or replaces the profile.
+	Save(ctx context.Context, profile *model.Profile) error
+	Delete(ctx context.Context, userID string) error
+}
+
+// MemoryProfileRepo is an in-memory ProfileRepo.
+type MemoryProfileRepo struct {
+	mu       sync.RWMutex
+	byUserID map[string]model.Profile
+}
+
+func NewMemoryProfileRepo() *MemoryProfileRepo {
+	return &MemoryProfileRepo{
+		byUserID: make(map[string]model.Profile),
+	}
+}
+
+func (r *MemoryProfileRepo) FindByUserID(_ context.Context, userID string) (*model.Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profile, ok := r.byUserID[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &profile, nil
+}
+
+func (r *MemoryProfileRepo) Save(_ context.Context, profile *model.Profile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byUserID[profile.UserID] = *profile
+	return nil
+}
+
+func (r *MemoryProfileRepo) Delete(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.byUserID, userID)
+	return nil
+}
@@ -0,0 +1,124 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hotpot/internal/pkg/user/model"
+	"image"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAvatarTooLarge   = errors.New("picture is too large")
+	ErrUnsupportedImage = errors.New("picture must be a JPEG, PNG or WebP image")
+)
+
+// avatarMaxPixels bounds the decoded size of uploads, so that a small file
+// cannot expand into gigabytes of pixels.
+const avatarMaxPixels = 50_000_000
+
+// UploadAvatar replaces the user's picture with an uploaded image. Variants
+// of every size in model.AvatarSizes are stored, stripped of metadata.
+func (svc *UserSvc) UploadAvatar(ctx context.Context, userID string, r io.Reader) (*model.Profile, error) {
+	// Read one byte more than allowed to tell whether the file is too large.
+	data, err := io.ReadAll(io.LimitReader(r, int64(svc.cfg.AvatarMaxBytes)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > svc.cfg.AvatarMaxBytes {
+		return nil, ErrAvatarTooLarge
+	}
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png", "image/webp":
+	default:
+		return nil, ErrUnsupportedImage
+	}
+
+	conf, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if conf.Width*conf.Height > avatarMaxPixels {
+		return nil, ErrAvatarTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	profile, err := svc.Profile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every upload gets new keys, so cached copies of the old picture are never served.
+	version := uuid.NewString()
+	orientation := jpegOrientation(data)
+	keys := make(map[int]string, len(model.AvatarSizes))
+	for _, size := range model.AvatarSizes {
+		encoded, contentType, ext, err := encodeVariant(squareVariant(src, size, orientation))
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("avatars/%s/%s/%d.%s", userID, version, size, ext)
+		if err := svc.blobs.Put(ctx, key, encoded, contentType); err != nil {
+			svc.deleteAvatar(ctx, keys)
+			return nil, err
+		}
+		keys[size] = key
+	}
+
+	old := profile.AvatarKeys
+	profile.AvatarKeys = keys
+	profile.AvatarURL = ""
+	if err := svc.saveProfile(ctx, profile); err != nil {
+		svc.deleteAvatar(ctx, keys)
+		return nil, err
+	}
+	svc.deleteAvatar(ctx, old)
+	return profile, nil
+}
+
+// AvatarURLs returns links to the profile's picture by edge length. Uploaded
+// pictures get signed links valid for the configured time.
+func (svc *UserSvc) AvatarURLs(ctx context.Context, profile *model.Profile) (map[string]string, error) {
+	if len(profile.AvatarKeys) == 0 {
+		return nil, nil
+	}
+
+	urls := make(map[string]string, len(profile.AvatarKeys))
+	for size, key := range profile.AvatarKeys {
+		u, err := svc.blobs.SignedURL(ctx, key, svc.cfg.AvatarURLTTL)
+		if err != nil {
+			return nil, err
+		}
+		urls[strconv.Itoa(size)] = u
+	}
+	return urls, nil
+}
+
+// deleteAvatar removes stored variants. Failures only leave unreachable
+// files behind, so they are logged rather than returned.
+func (svc *UserSvc) deleteAvatar(ctx context.Context, keys map[int]string) {
+	for _, key := range keys {
+		if err := svc.blobs.Delete(ctx, key); err != nil {
+			svc.logger.Error("delete avatar failed", "key", key, "error", err)
+		}
+	}
+}
+
+func (svc *UserSvc) saveProfile(ctx context.Context, profile *model.Profile) error {
+	now := time.Now().UTC()
+	if profile.CreatedAt.IsZero() {
+		profile.CreatedAt = now
+	}
+	profile.UpdatedAt = now
+	return svc.store.Profiles.Save(ctx, profile)
+}
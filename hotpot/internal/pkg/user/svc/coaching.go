@@ -0,0 +1,349 @@
+package svc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hotpot/internal/core/utils/mailer"
+	authmodel "hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/repo"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationExpired  = errors.New("invitation has expired")
+	ErrSelfCoaching       = errors.New("you cannot coach yourself")
+	ErrAlreadyCoached     = errors.New("already coached by this dietitian")
+	ErrCoachingNotFound   = errors.New("coaching relationship not found")
+	// ErrAccessDenied is returned by AuthorizeClientAccess when the caller
+	// may not access the data of the user.
+	ErrAccessDenied = errors.New("no access to this user's data")
+)
+
+const (
+	invitationTTL = 7 * 24 * time.Hour
+	// Invitation codes are shown as XXXX-XXXX.
+	invitationCodeLen = 8
+)
+
+// Resources a coach can be given access to, named in data access audit events.
+const (
+	ResourceProfile = "profile"
+	ResourceMetrics = "metrics"
+	ResourceMeals   = "meals"
+	ResourceDiets   = "diets"
+)
+
+// Invite creates an invitation from the coach and returns it together with
+// its code, which is shown only once. When email is not empty only the
+// account with that address can accept it, and the invitation is emailed
+// there.
+func (svc *UserSvc) Invite(
+	ctx context.Context,
+	coachID, email string,
+	access model.CoachingAccess,
+) (*model.CoachingLink, string, error) {
+	code, err := newInvitationCode()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	link := &model.CoachingLink{
+		ID:          uuid.NewString(),
+		CoachID:     coachID,
+		InviteEmail: strings.ToLower(strings.TrimSpace(email)),
+		CodeHash:    hashInvitationCode(code),
+		Access:      access,
+		Status:      model.CoachingPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(invitationTTL),
+	}
+	if err := svc.store.Coaching.Create(ctx, link); err != nil {
+		return nil, "", err
+	}
+
+	svc.logger.Info("coaching invitation created", "coach_id", coachID, "invitation_id", link.ID)
+	svc.auth.RecordAudit(ctx, authmodel.AuditCoachingChange, coachID, authmodel.AuditSuccess,
+		map[string]string{"action": "invite", "invitation_id": link.ID, "access": string(access)})
+
+	code = code[:invitationCodeLen/2] + "-" + code[invitationCodeLen/2:]
+	if link.InviteEmail != "" {
+		// The coach still gets the code to pass on if the email is lost.
+		if err := svc.sendInvitation(ctx, link, code); err != nil {
+			svc.logger.Error("coaching invitation email failed", "invitation_id", link.ID, "error", err)
+		}
+	}
+	return link, code, nil
+}
+
+// sendInvitation emails an invitation to the address it is meant for.
+func (svc *UserSvc) sendInvitation(ctx context.Context, link *model.CoachingLink, code string) error {
+	coach, err := svc.auth.AccountEmail(ctx, link.CoachID)
+	if err != nil {
+		return err
+	}
+	if profile, err := svc.store.Profiles.FindByUserID(ctx, link.CoachID); err == nil && profile.DisplayName != "" {
+		coach = profile.DisplayName + " (" + coach + ")"
+	}
+
+	return svc.mailer.Send(ctx, mailer.Message{
+		To:      link.InviteEmail,
+		Subject: "You are invited to be coached on HotPot",
+		Body: fmt.Sprintf("%s invited you to be coached on HotPot, with %s access to your profile, body metrics, meals and diets.\n\n"+
+			"Sign in to HotPot with this address to accept or decline the invitation, or redeem this code:\n%s\n\n"+
+			"The invitation expires in %s. If you don't know the sender, ignore this email.\n",
+			coach, link.Access, code, invitationTTL),
+	})
+}
+
+// Invitations returns the coach's invitations that are still waiting for an answer.
+func (svc *UserSvc) Invitations(ctx context.Context, coachID string) ([]model.CoachingLink, error) {
+	return svc.store.Coaching.ListByCoach(ctx, coachID, model.CoachingPending)
+}
+
+// CancelInvitation withdraws one of the coach's pending invitations.
+func (svc *UserSvc) CancelInvitation(ctx context.Context, coachID, id string) error {
+	link, err := svc.pendingInvitation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if link.CoachID != coachID {
+		return ErrInvitationNotFound
+	}
+	return svc.endLink(ctx, link, model.CoachingDeclined, "cancel")
+}
+
+// ReceivedInvitations returns the pending invitations addressed to the user's email.
+func (svc *UserSvc) ReceivedInvitations(ctx context.Context, userID string) ([]model.CoachingLink, error) {
+	email, err := svc.auth.AccountEmail(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := svc.store.Coaching.ListPendingByEmail(ctx, strings.ToLower(email))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	valid := links[:0]
+	for _, link := range links {
+		if now.Before(link.ExpiresAt) {
+			valid = append(valid, link)
+		}
+	}
+	return valid, nil
+}
+
+// AcceptInvitation accepts an invitation addressed to the user's email.
+func (svc *UserSvc) AcceptInvitation(ctx context.Context, clientID, id string) (*model.CoachingLink, error) {
+	link, err := svc.pendingInvitation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if link.InviteEmail == "" {
+		// Open invitations can only be redeemed with their code.
+		return nil, ErrInvitationNotFound
+	}
+	return svc.accept(ctx, clientID, link)
+}
+
+// RedeemInvitationCode accepts the invitation with the given code.
+func (svc *UserSvc) RedeemInvitationCode(ctx context.Context, clientID, code string) (*model.CoachingLink, error) {
+	link, err := svc.store.Coaching.FindByCodeHash(ctx, hashInvitationCode(normalizeInvitationCode(code)))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	if link.Status != model.CoachingPending {
+		return nil, ErrInvitationNotFound
+	}
+	return svc.accept(ctx, clientID, link)
+}
+
+// DeclineInvitation declines an invitation addressed to the user's email.
+func (svc *UserSvc) DeclineInvitation(ctx context.Context, clientID, id string) error {
+	link, err := svc.pendingInvitation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if link.InviteEmail == "" {
+		// Open invitations are not addressed to anyone who could decline them.
+		return ErrInvitationNotFound
+	}
+	if err := svc.checkInvitee(ctx, clientID, link); err != nil {
+		return err
+	}
+	link.ClientID = clientID
+	return svc.endLink(ctx, link, model.CoachingDeclined, "decline")
+}
+
+// Clients returns the coach's active relationships. Listing them counts as
+// access to the clients' data and is audited.
+func (svc *UserSvc) Clients(ctx context.Context, coachID string) ([]model.CoachingLink, error) {
+	links, err := svc.store.Coaching.ListByCoach(ctx, coachID, model.CoachingActive)
+	if err != nil {
+		return nil, err
+	}
+	svc.auth.RecordAudit(ctx, authmodel.AuditDataAccess, coachID, authmodel.AuditSuccess,
+		map[string]string{"resource": "client_list"})
+	return links, nil
+}
+
+// Coaches returns the user's active relationships with coaches.
+func (svc *UserSvc) Coaches(ctx context.Context, clientID string) ([]model.CoachingLink, error) {
+	return svc.store.Coaching.ListByClient(ctx, clientID, model.CoachingActive)
+}
+
+// EndCoaching ends the active relationship between the coach and the
+// client. Either side may end it at any time.
+func (svc *UserSvc) EndCoaching(ctx context.Context, coachID, clientID string) error {
+	link, err := svc.store.Coaching.FindActive(ctx, coachID, clientID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrCoachingNotFound
+		}
+		return err
+	}
+	return svc.endLink(ctx, link, model.CoachingRevoked, "revoke")
+}
+
+// AuthorizeClientAccess checks that the caller may access a resource of the
+// user: either it is their own data, or the user has accepted the caller as
+// their coach with enough access. Access by coaches is audited. Modules holding
+// per-user data call it before serving another user's records; routes should
+// also require the clients:manage permission so that former dietitians are
+// shut out.
+func (svc *UserSvc) AuthorizeClientAccess(
+	ctx context.Context,
+	callerID, userID, resource string,
+	access model.CoachingAccess,
+) error {
+	if callerID == userID {
+		return nil
+	}
+
+	details := map[string]string{"resource": resource, "access": string(access)}
+	link, err := svc.store.Coaching.FindActive(ctx, callerID, userID)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return err
+	}
+	if err != nil || !link.Grants(access) {
+		svc.auth.RecordAudit(ctx, authmodel.AuditDataAccess, userID, authmodel.AuditFailure, details)
+		return ErrAccessDenied
+	}
+	svc.auth.RecordAudit(ctx, authmodel.AuditDataAccess, userID, authmodel.AuditSuccess, details)
+	return nil
+}
+
+func (svc *UserSvc) pendingInvitation(ctx context.Context, id string) (*model.CoachingLink, error) {
+	link, err := svc.store.Coaching.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	if link.Status != model.CoachingPending {
+		return nil, ErrInvitationNotFound
+	}
+	return link, nil
+}
+
+// checkInvitee makes sure an invitation addressed to an email is answered
+// by the account with that email.
+func (svc *UserSvc) checkInvitee(ctx context.Context, userID string, link *model.CoachingLink) error {
+	if link.InviteEmail == "" {
+		return nil
+	}
+	email, err := svc.auth.AccountEmail(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(email, link.InviteEmail) {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+func (svc *UserSvc) accept(ctx context.Context, clientID string, link *model.CoachingLink) (*model.CoachingLink, error) {
+	if err := svc.checkInvitee(ctx, clientID, link); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if now.After(link.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+	if link.CoachID == clientID {
+		return nil, ErrSelfCoaching
+	}
+	if _, err := svc.store.Coaching.FindActive(ctx, link.CoachID, clientID); err == nil {
+		return nil, ErrAlreadyCoached
+	} else if !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	link.ClientID = clientID
+	link.Status = model.CoachingActive
+	link.AcceptedAt = &now
+	if err := svc.store.Coaching.Update(ctx, link); err != nil {
+		return nil, err
+	}
+
+	svc.logger.Info("coaching started", "coach_id", link.CoachID, "client_id", clientID)
+	svc.auth.RecordAudit(ctx, authmodel.AuditCoachingChange, clientID, authmodel.AuditSuccess,
+		map[string]string{"action": "accept", "invitation_id": link.ID, "coach_id": link.CoachID, "access": string(link.Access)})
+	return link, nil
+}
+
+func (svc *UserSvc) endLink(ctx context.Context, link *model.CoachingLink, status model.CoachingStatus, action string) error {
+	now := time.Now().UTC()
+	link.Status = status
+	link.EndedAt = &now
+	if err := svc.store.Coaching.Update(ctx, link); err != nil {
+		return err
+	}
+
+	subjectID := link.ClientID
+	if subjectID == "" {
+		subjectID = link.CoachID
+	}
+	svc.logger.Info("coaching ended", "coach_id", link.CoachID, "client_id", link.ClientID, "action", action)
+	svc.auth.RecordAudit(ctx, authmodel.AuditCoachingChange, subjectID, authmodel.AuditSuccess,
+		map[string]string{"action": action, "invitation_id": link.ID, "coach_id": link.CoachID})
+	return nil
+}
+
+func newInvitationCode() (string, error) {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	b := make([]byte, invitationCodeLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b), nil
+}
+
+// normalizeInvitationCode undoes the formatting users add when typing a code.
+func normalizeInvitationCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func hashInvitationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,109 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/repo"
+	"time"
+)
+
+var ErrNoDeletionScheduled = errors.New("no account deletion is scheduled")
+
+// RequestDeletion schedules the account and all its data for erasure once
+// the grace period has passed. Repeated requests keep the original schedule.
+func (svc *UserSvc) RequestDeletion(ctx context.Context, userID string) (*model.DeletionRequest, error) {
+	if req, err := svc.store.Deletions.FindByUserID(ctx, userID); err == nil {
+		return req, nil
+	} else if !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	req := &model.DeletionRequest{
+		UserID:       userID,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(svc.cfg.AccountDeletionGrace),
+	}
+	if err := svc.store.Deletions.Save(ctx, req); err != nil {
+		return nil, err
+	}
+
+	svc.logger.Info("account deletion scheduled", "user_id", userID, "scheduled_for", req.ScheduledFor)
+	return req, nil
+}
+
+// Deletion returns the user's pending deletion request.
+func (svc *UserSvc) Deletion(ctx context.Context, userID string) (*model.DeletionRequest, error) {
+	req, err := svc.store.Deletions.FindByUserID(ctx, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrNoDeletionScheduled
+	}
+	return req, err
+}
+
+// CancelDeletion keeps the account after all.
+func (svc *UserSvc) CancelDeletion(ctx context.Context, userID string) error {
+	if err := svc.store.Deletions.Delete(ctx, userID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNoDeletionScheduled
+		}
+		return err
+	}
+
+	svc.logger.Info("account deletion canceled", "user_id", userID)
+	return nil
+}
+
+// ProcessDueDeletions erases every account whose grace period is over.
+// Failed erasures stay scheduled and are retried on the next run.
+func (svc *UserSvc) ProcessDueDeletions(ctx context.Context) error {
+	due, err := svc.store.Deletions.ListDue(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, req := range due {
+		if err := svc.userData.Erase(ctx, req.UserID); err != nil {
+			errs = append(errs, err)
+			svc.logger.Error("account deletion failed", "user_id", req.UserID, "error", err)
+			continue
+		}
+		if err := svc.store.Deletions.Delete(ctx, req.UserID); err != nil && !errors.Is(err, repo.ErrNotFound) {
+			errs = append(errs, err)
+			continue
+		}
+		svc.logger.Info("account deleted", "user_id", req.UserID)
+	}
+	return errors.Join(errs...)
+}
+
+// EraseUserData deletes the user's profile and picture, preferences, body
+// metrics, coaching relationships and export archives.
+func (svc *UserSvc) EraseUserData(ctx context.Context, userID string) error {
+	profile, err := svc.store.Profiles.FindByUserID(ctx, userID)
+	switch {
+	case err == nil:
+		for _, key := range profile.AvatarKeys {
+			if err := svc.blobs.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+	case !errors.Is(err, repo.ErrNotFound):
+		return err
+	}
+	if err := svc.store.Profiles.Delete(ctx, userID); err != nil {
+		return err
+	}
+	if err := svc.store.Preferences.Delete(ctx, userID); err != nil {
+		return err
+	}
+	if err := svc.store.Measurements.DeleteByUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := svc.store.Coaching.DeleteByUser(ctx, userID); err != nil {
+		return err
+	}
+	return svc.store.Exports.DeleteByUser(ctx, userID)
+}
@@ -0,0 +1,175 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hotpot/internal/pkg/user/model"
+	"math"
+	"strings"
+	"time"
+)
+
+// Formula names a basal metabolic rate equation.
+type Formula string
+
+const (
+	FormulaMifflinStJeor  Formula = "mifflin_st_jeor"
+	FormulaHarrisBenedict Formula = "harris_benedict"
+	// FormulaKatchMcArdle uses lean body mass and needs the body fat percentage.
+	FormulaKatchMcArdle Formula = "katch_mcardle"
+)
+
+var ErrFormulaUnavailable = errors.New("formula needs a recent body fat measurement")
+
+// bodyFatMaxAge is how old a body fat measurement may be, relative to the
+// weight, to be used for the energy needs.
+const bodyFatMaxAge = 30 * 24 * time.Hour
+
+// IncompleteProfileError lists the inputs missing to compute energy needs.
+type IncompleteProfileError struct {
+	Missing []string
+}
+
+func (e *IncompleteProfileError) Error() string {
+	return fmt.Sprintf("profile is missing %s", strings.Join(e.Missing, ", "))
+}
+
+// EnergyInput holds what the BMR equations need, in metric units.
+type EnergyInput struct {
+	Sex        model.Sex
+	Age        int
+	WeightKg   float64
+	HeightCm   float64
+	BodyFatPct *float64
+	Activity   model.ActivityLevel
+}
+
+// EnergyNeeds is the daily energy expenditure of a user, in kcal.
+type EnergyNeeds struct {
+	Input EnergyInput
+	// BMR holds the result of every equation the input allows.
+	BMR map[Formula]float64
+	// Formula is the equation BMRKcal and TDEEKcal are based on.
+	Formula  Formula
+	BMRKcal  float64
+	TDEEKcal float64
+}
+
+// EnergyNeeds computes the energy needs of the user from their profile and
+// the trend of their body metrics. An empty formula picks Katch-McArdle when
+// body fat was measured recently and Mifflin-St Jeor otherwise. The diet
+// module calls it to derive calorie targets.
+func (svc *UserSvc) EnergyNeeds(ctx context.Context, userID string, formula Formula) (*EnergyNeeds, error) {
+	profile, err := svc.Profile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	points, err := svc.Measurements(ctx, userID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	in := EnergyInput{
+		Sex:      profile.Sex,
+		Age:      profile.Age(time.Now().UTC()),
+		Activity: profile.Activity,
+	}
+	var missing []string
+	if profile.BirthDate == nil {
+		missing = append(missing, "birth_date")
+	}
+	if profile.Sex == "" {
+		missing = append(missing, "sex")
+	}
+	if profile.HeightCm == nil {
+		missing = append(missing, "height_cm")
+	} else {
+		in.HeightCm = *profile.HeightCm
+	}
+	// The trend of the newest point smooths out day-to-day water weight.
+	// The body fat trend only counts when body fat was measured within
+	// bodyFatMaxAge of that weight, as it never decays on its own.
+	for i := len(points) - 1; i >= 0; i-- {
+		if points[i].WeightTrend == nil {
+			continue
+		}
+		in.WeightKg = *points[i].WeightTrend
+		for j := i; j >= 0 && points[i].MeasuredAt.Sub(points[j].MeasuredAt) <= bodyFatMaxAge; j-- {
+			if points[j].BodyFatPct != nil {
+				in.BodyFatPct = points[i].BodyFatTrend
+				break
+			}
+		}
+		break
+	}
+	if in.WeightKg == 0 {
+		missing = append(missing, "weight")
+	}
+	if len(missing) > 0 {
+		return nil, &IncompleteProfileError{Missing: missing}
+	}
+
+	return CalculateEnergy(in, formula)
+}
+
+// CalculateEnergy computes the basal metabolic rate with every applicable
+// equation and the total daily energy expenditure from the activity level.
+func CalculateEnergy(in EnergyInput, formula Formula) (*EnergyNeeds, error) {
+	bmr := map[Formula]float64{
+		FormulaMifflinStJeor:  mifflinStJeor(in),
+		FormulaHarrisBenedict: harrisBenedict(in),
+	}
+	if in.BodyFatPct != nil {
+		bmr[FormulaKatchMcArdle] = katchMcArdle(in)
+	}
+
+	if formula == "" {
+		formula = FormulaMifflinStJeor
+		if in.BodyFatPct != nil {
+			formula = FormulaKatchMcArdle
+		}
+	}
+	value, ok := bmr[formula]
+	if !ok {
+		return nil, ErrFormulaUnavailable
+	}
+
+	factor := in.Activity.Factor()
+	if factor == 0 {
+		factor = model.ActivitySedentary.Factor()
+	}
+	for f, v := range bmr {
+		bmr[f] = math.Round(v)
+	}
+	return &EnergyNeeds{
+		Input:    in,
+		BMR:      bmr,
+		Formula:  formula,
+		BMRKcal:  math.Round(value),
+		TDEEKcal: math.Round(value * factor),
+	}, nil
+}
+
+// mifflinStJeor is the Mifflin-St Jeor equation (1990).
+func mifflinStJeor(in EnergyInput) float64 {
+	bmr := 10*in.WeightKg + 6.25*in.HeightCm - 5*float64(in.Age)
+	if in.Sex == model.SexMale {
+		return bmr + 5
+	}
+	return bmr - 161
+}
+
+// harrisBenedict is the Harris-Benedict equation as revised by Roza and Shizgal (1984).
+func harrisBenedict(in EnergyInput) float64 {
+	if in.Sex == model.SexMale {
+		return 88.362 + 13.397*in.WeightKg + 4.799*in.HeightCm - 5.677*float64(in.Age)
+	}
+	return 447.593 + 9.247*in.WeightKg + 3.098*in.HeightCm - 4.330*float64(in.Age)
+}
+
+// katchMcArdle is the Katch-McArdle equation based on lean body mass.
+func katchMcArdle(in EnergyInput) float64 {
+	leanMass := in.WeightKg * (1 - *in.BodyFatPct/100)
+	return 370 + 21.6*leanMass
+}
@@ -0,0 +1,217 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/core/utils/userdata"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/repo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrExportNotFound   = errors.New("export not found")
+	ErrExportInProgress = errors.New("an export is already in progress")
+)
+
+const (
+	// exportTTL is how long a finished archive can be downloaded.
+	exportTTL = 24 * time.Hour
+	// exportTimeout bounds a single export job.
+	exportTimeout = 5 * time.Minute
+)
+
+// StartExport queues an export of everything stored about the user and
+// returns the job. The archive is built in the background.
+func (svc *UserSvc) StartExport(ctx context.Context, userID string) (*model.ExportJob, error) {
+	now := time.Now().UTC()
+	if err := svc.store.Exports.DeleteExpired(ctx, now); err != nil {
+		return nil, err
+	}
+	if _, err := svc.store.Exports.FindPending(ctx, userID); err == nil {
+		return nil, ErrExportInProgress
+	} else if !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	job := &model.ExportJob{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Status:    model.ExportPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(exportTTL),
+	}
+	if err := svc.store.Exports.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	go svc.runExport(*job)
+	return job, nil
+}
+
+// Export returns one of the user's export jobs.
+func (svc *UserSvc) Export(ctx context.Context, userID, id string) (*model.ExportJob, error) {
+	job, err := svc.store.Exports.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+	if job.UserID != userID || time.Now().After(job.ExpiresAt) {
+		return nil, ErrExportNotFound
+	}
+	return job, nil
+}
+
+// runExport builds the archive of a job. It outlives the request that
+// started it, so it runs with its own context.
+func (svc *UserSvc) runExport(job model.ExportJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	archive, err := svc.userData.Export(ctx, job.UserID)
+	now := time.Now().UTC()
+	job.CompletedAt = &now
+	job.ExpiresAt = now.Add(exportTTL)
+	if err != nil {
+		svc.logger.Error("user data export failed", "user_id", job.UserID, "export_id", job.ID, "error", err)
+		job.Status = model.ExportFailed
+	} else {
+		job.Status = model.ExportDone
+		job.Archive = archive
+	}
+
+	if err := svc.store.Exports.Update(ctx, &job); err != nil {
+		svc.logger.Error("save export job failed", "export_id", job.ID, "error", err)
+		return
+	}
+	svc.logger.Info("user data export finished", "user_id", job.UserID, "export_id", job.ID, "status", job.Status)
+}
+
+type profileRecord struct {
+	UserID        string     `json:"user_id"`
+	DisplayName   string     `json:"display_name"`
+	BirthDate     *time.Time `json:"birth_date"`
+	Sex           string     `json:"sex"`
+	HeightCm      *float64   `json:"height_cm"`
+	Units         string     `json:"units"`
+	ActivityLevel string     `json:"activity_level"`
+	Timezone      string     `json:"timezone"`
+	AvatarURL     string     `json:"avatar_url"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type preferencesRecord struct {
+	Restrictions []model.Restriction `json:"restrictions"`
+	Allergens    []model.Allergen    `json:"allergens"`
+	Dislikes     []string            `json:"dislikes"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
+
+type measurementRecord struct {
+	ID         string    `json:"id"`
+	MeasuredAt time.Time `json:"measured_at"`
+	WeightKg   *float64  `json:"weight_kg"`
+	BodyFatPct *float64  `json:"body_fat_pct"`
+	WaistCm    *float64  `json:"waist_cm"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type coachingRecord struct {
+	ID         string     `json:"id"`
+	CoachID    string     `json:"coach_id"`
+	ClientID   string     `json:"client_id"`
+	Access     string     `json:"access"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+}
+
+// ExportUserData returns the profile, preferences, body metrics and coaching
+// relationships of the user.
+func (svc *UserSvc) ExportUserData(ctx context.Context, userID string) ([]userdata.Dataset, error) {
+	var profiles []profileRecord
+	p, err := svc.store.Profiles.FindByUserID(ctx, userID)
+	switch {
+	case err == nil:
+		profiles = append(profiles, profileRecord{
+			UserID:        p.UserID,
+			DisplayName:   p.DisplayName,
+			BirthDate:     p.BirthDate,
+			Sex:           string(p.Sex),
+			HeightCm:      p.HeightCm,
+			Units:         string(p.Units),
+			ActivityLevel: string(p.Activity),
+			Timezone:      p.Timezone,
+			AvatarURL:     p.AvatarURL,
+			CreatedAt:     p.CreatedAt,
+			UpdatedAt:     p.UpdatedAt,
+		})
+	case !errors.Is(err, repo.ErrNotFound):
+		return nil, err
+	}
+
+	var preferences []preferencesRecord
+	prefs, err := svc.store.Preferences.FindByUserID(ctx, userID)
+	switch {
+	case err == nil:
+		preferences = append(preferences, preferencesRecord{
+			Restrictions: prefs.Restrictions,
+			Allergens:    prefs.Allergens,
+			Dislikes:     prefs.Dislikes,
+			UpdatedAt:    prefs.UpdatedAt,
+		})
+	case !errors.Is(err, repo.ErrNotFound):
+		return nil, err
+	}
+
+	list, err := svc.store.Measurements.ListByUser(ctx, userID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	measurements := make([]measurementRecord, len(list))
+	for i, m := range list {
+		measurements[i] = measurementRecord{
+			ID:         m.ID,
+			MeasuredAt: m.MeasuredAt,
+			WeightKg:   m.WeightKg,
+			BodyFatPct: m.BodyFatPct,
+			WaistCm:    m.WaistCm,
+			CreatedAt:  m.CreatedAt,
+		}
+	}
+
+	asCoach, err := svc.store.Coaching.ListByCoach(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	asClient, err := svc.store.Coaching.ListByClient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var coaching []coachingRecord
+	for _, l := range append(asCoach, asClient...) {
+		coaching = append(coaching, coachingRecord{
+			ID:         l.ID,
+			CoachID:    l.CoachID,
+			ClientID:   l.ClientID,
+			Access:     string(l.Access),
+			Status:     string(l.Status),
+			CreatedAt:  l.CreatedAt,
+			AcceptedAt: l.AcceptedAt,
+			EndedAt:    l.EndedAt,
+		})
+	}
+
+	return []userdata.Dataset{
+		{Name: "user/profile", Records: profiles},
+		{Name: "user/preferences", Records: preferences},
+		{Name: "user/metrics", Records: measurements},
+		{Name: "user/coaching", Records: coaching},
+	}, nil
+}
@@ -0,0 +1,138 @@
+package svc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Registers the WebP decoder.
+)
+
+// jpegQuality is used for avatar variants without transparency.
+const jpegQuality = 85
+
+// squareVariant center-crops src to a square and scales it to size x size,
+// then applies the EXIF orientation. Cropping and scaling a square commute
+// with rotations and flips, so only the small result has to be transformed.
+func squareVariant(src image.Image, size int, orientation int) *image.RGBA {
+	b := src.Bounds()
+	edge := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-edge)/2
+	y0 := b.Min.Y + (b.Dy()-edge)/2
+	crop := image.Rect(x0, y0, x0+edge, y0+edge)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return orient(dst, orientation)
+}
+
+// encodeVariant encodes img as PNG when it has transparency and as JPEG
+// otherwise. Encoding from pixels drops all metadata of the upload.
+func encodeVariant(img *image.RGBA) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if !img.Opaque() {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/png", "png", nil
+	}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/jpeg", "jpg", nil
+}
+
+// orient turns an image stored with the given EXIF orientation (1-8) upright.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	n := img.Bounds().Dx() // Variants are square.
+	dst := image.NewRGBA(img.Bounds())
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Mirrored horizontally.
+				dx, dy = n-1-x, y
+			case 3: // Rotated 180°.
+				dx, dy = n-1-x, n-1-y
+			case 4: // Mirrored vertically.
+				dx, dy = x, n-1-y
+			case 5: // Mirrored along the top-left diagonal.
+				dx, dy = y, x
+			case 6: // Needs a clockwise quarter turn.
+				dx, dy = n-1-y, x
+			case 7: // Mirrored along the top-right diagonal.
+				dx, dy = n-1-y, n-1-x
+			case 8: // Needs a counter-clockwise quarter turn.
+				dx, dy = y, n-1-x
+			}
+			dst.SetRGBA(dx, dy, img.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation tag of a JPEG file, or 1 when
+// there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xD9 || marker == 0xDA { // End of image or start of scan.
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		segment := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i = end
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag (0x0112) from the first IFD of
+// the TIFF structure inside an EXIF segment.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 1
+}
@@ -0,0 +1,141 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/repo"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMeasurementNotFound = errors.New("measurement not found")
+	ErrEmptyMeasurement    = errors.New("at least one of weight, body fat or waist is required")
+	ErrFutureMeasurement   = errors.New("measurement cannot be taken in the future")
+)
+
+// trendAlpha is the share of a new value that goes into the trend when
+// measurements are one day apart, as popularised by The Hacker's Diet.
+const trendAlpha = 0.1
+
+// futureSkew tolerates clocks of client devices running slightly ahead.
+const futureSkew = 5 * time.Minute
+
+// MetricPoint is a measurement with the trend of each value at that time.
+// Trends are nil for values that have never been measured.
+type MetricPoint struct {
+	model.Measurement
+	WeightTrend  *float64
+	BodyFatTrend *float64
+	WaistTrend   *float64
+}
+
+// RecordMeasurement adds a measurement to the user's history. A zero
+// measuredAt means now.
+func (svc *UserSvc) RecordMeasurement(
+	ctx context.Context,
+	userID string,
+	measuredAt time.Time,
+	weightKg, bodyFatPct, waistCm *float64,
+) (*model.Measurement, error) {
+	if weightKg == nil && bodyFatPct == nil && waistCm == nil {
+		return nil, ErrEmptyMeasurement
+	}
+	now := time.Now().UTC()
+	if measuredAt.IsZero() {
+		measuredAt = now
+	}
+	if measuredAt.After(now.Add(futureSkew)) {
+		return nil, ErrFutureMeasurement
+	}
+
+	m := &model.Measurement{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		MeasuredAt: measuredAt.UTC(),
+		WeightKg:   weightKg,
+		BodyFatPct: bodyFatPct,
+		WaistCm:    waistCm,
+		CreatedAt:  now,
+	}
+	if err := svc.store.Measurements.Create(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Measurements returns the user's measurements taken in [from, to), oldest
+// first, with trend lines. Zero bounds are open. Trends are computed over
+// the whole history, so the first points of a range are already smoothed.
+func (svc *UserSvc) Measurements(ctx context.Context, userID string, from, to time.Time) ([]MetricPoint, error) {
+	list, err := svc.store.Measurements.ListByUser(ctx, userID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var weight, bodyFat, waist ewma
+	points := []MetricPoint{}
+	for _, m := range list {
+		p := MetricPoint{
+			Measurement:  m,
+			WeightTrend:  weight.add(m.MeasuredAt, m.WeightKg),
+			BodyFatTrend: bodyFat.add(m.MeasuredAt, m.BodyFatPct),
+			WaistTrend:   waist.add(m.MeasuredAt, m.WaistCm),
+		}
+		if from.IsZero() || !m.MeasuredAt.Before(from) {
+			points = append(points, p)
+		}
+	}
+	return points, nil
+}
+
+// DeleteMeasurement removes one of the user's measurements.
+func (svc *UserSvc) DeleteMeasurement(ctx context.Context, userID, id string) error {
+	m, err := svc.store.Measurements.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrMeasurementNotFound
+		}
+		return err
+	}
+	if m.UserID != userID {
+		return ErrMeasurementNotFound
+	}
+	return svc.store.Measurements.Delete(ctx, id)
+}
+
+// ewma is an exponentially weighted moving average over irregularly spaced
+// samples. The weight of a new sample grows with the time since the last
+// one, so a week without weighing counts like seven daily steps.
+type ewma struct {
+	value float64
+	last  time.Time
+	set   bool
+}
+
+// add feeds a sample, if present, and returns the current trend.
+func (e *ewma) add(at time.Time, sample *float64) *float64 {
+	if sample != nil {
+		if !e.set {
+			e.value, e.set = *sample, true
+		} else {
+			days := math.Max(at.Sub(e.last).Hours()/24, 0)
+			// Several samples on the same day each count as a full day's step,
+			// otherwise they would barely move the trend.
+			alpha := trendAlpha
+			if days > 1 {
+				alpha = 1 - math.Pow(1-trendAlpha, days)
+			}
+			e.value += alpha * (*sample - e.value)
+		}
+		e.last = at
+	}
+	if !e.set {
+		return nil
+	}
+	v := math.Round(e.value*100) / 100
+	return &v
+}
@@ -0,0 +1,64 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/repo"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Preferences returns the user's dietary preferences, which are empty until
+// the user sets them.
+func (svc *UserSvc) Preferences(ctx context.Context, userID string) (*model.Preferences, error) {
+	prefs, err := svc.store.Preferences.FindByUserID(ctx, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return &model.Preferences{UserID: userID}, nil
+	}
+	return prefs, err
+}
+
+// SetPreferences replaces the user's dietary preferences.
+func (svc *UserSvc) SetPreferences(
+	ctx context.Context,
+	userID string,
+	restrictions []model.Restriction,
+	allergens []model.Allergen,
+	dislikes []string,
+) (*model.Preferences, error) {
+	slices.Sort(restrictions)
+	slices.Sort(allergens)
+
+	cleaned := make([]string, 0, len(dislikes))
+	for _, d := range dislikes {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			cleaned = append(cleaned, d)
+		}
+	}
+	slices.Sort(cleaned)
+
+	prefs := &model.Preferences{
+		UserID:       userID,
+		Restrictions: slices.Compact(restrictions),
+		Allergens:    slices.Compact(allergens),
+		Dislikes:     slices.Compact(cleaned),
+		UpdatedAt:    time.Now().UTC(),
+	}
+	if err := svc.store.Preferences.Save(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// CheckFood returns the user's preferences the food violates; an empty
+// result means it can be offered. Meal and diet planning call it before
+// suggesting a food.
+func (svc *UserSvc) CheckFood(ctx context.Context, userID string, food model.Food) ([]model.Violation, error) {
+	prefs, err := svc.Preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return prefs.Violations(food), nil
+}
@@ -0,0 +1,195 @@
+package svc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	authmodel "hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/repo"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// sortKeyTimeLayout formats times so that they sort as strings.
+const sortKeyTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// UserQuery filters, orders and pages the admin user directory.
+type UserQuery struct {
+	Email  string
+	Role   authmodel.Role
+	Status model.UserStatus
+	// Name matches display names containing it, ignoring case.
+	Name        string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Sort        model.UserSort
+	Descending  bool
+	// Cursor continues the listing after the page it was returned with.
+	Cursor string
+	Limit  int
+}
+
+// UserSummary is a user as listed in the admin directory.
+type UserSummary struct {
+	authmodel.AccountSummary
+	DisplayName string
+	Status      model.UserStatus
+	// DeletionScheduledFor is set for users who asked to delete their account.
+	DeletionScheduledFor *time.Time
+}
+
+// userCursor is the position after the last user of a page. It carries the
+// sort order so that a cursor cannot be reused with a different one.
+type userCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   string `json:"id"`
+}
+
+// SearchUsers returns a page of the users matching the query and the cursor
+// of the next page, which is empty on the last page.
+func (svc *UserSvc) SearchUsers(ctx context.Context, q UserQuery) ([]UserSummary, string, error) {
+	order := string(q.Sort)
+	if q.Descending {
+		order = "-" + order
+	}
+	var after *userCursor
+	if q.Cursor != "" {
+		c, err := decodeUserCursor(q.Cursor)
+		if err != nil || c.Sort != order {
+			return nil, "", ErrInvalidCursor
+		}
+		after = c
+	}
+
+	accountQuery := authmodel.AccountQuery{
+		Email:       q.Email,
+		Role:        q.Role,
+		CreatedFrom: q.CreatedFrom,
+		CreatedTo:   q.CreatedTo,
+	}
+	switch q.Status {
+	case model.StatusActive:
+		verified := true
+		accountQuery.Verified = &verified
+	case model.StatusUnverified:
+		verified := false
+		accountQuery.Verified = &verified
+	}
+	accounts, err := svc.auth.SearchAccounts(ctx, accountQuery)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name := strings.ToLower(q.Name)
+	type entry struct {
+		user UserSummary
+		key  string
+	}
+	entries := make([]entry, 0, len(accounts))
+	for _, account := range accounts {
+		user, err := svc.userSummary(ctx, account)
+		if err != nil {
+			return nil, "", err
+		}
+		if q.Status != "" && user.Status != q.Status {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(user.DisplayName), name) {
+			continue
+		}
+		entries = append(entries, entry{user: *user, key: userSortKey(user, q.Sort)})
+	}
+
+	// IDs break ties so that every user has a unique position.
+	before := func(aKey, aID, bKey, bID string) bool {
+		if aKey != bKey {
+			return (aKey < bKey) != q.Descending
+		}
+		return (aID < bID) != q.Descending
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return before(entries[i].key, entries[i].user.ID, entries[j].key, entries[j].user.ID)
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(entries), func(i int) bool {
+			return before(after.Key, after.ID, entries[i].key, entries[i].user.ID)
+		})
+	}
+	end := min(start+q.Limit, len(entries))
+
+	users := make([]UserSummary, 0, end-start)
+	for _, e := range entries[start:end] {
+		users = append(users, e.user)
+	}
+
+	next := ""
+	if end < len(entries) {
+		last := entries[end-1]
+		next = encodeUserCursor(userCursor{Sort: order, Key: last.key, ID: last.user.ID})
+	}
+	svc.auth.RecordAudit(ctx, authmodel.AuditDataAccess, "", authmodel.AuditSuccess,
+		map[string]string{"resource": "user_directory"})
+	return users, next, nil
+}
+
+// userSummary joins an account with the user's profile and deletion request.
+func (svc *UserSvc) userSummary(ctx context.Context, account authmodel.AccountSummary) (*UserSummary, error) {
+	user := &UserSummary{AccountSummary: account, Status: model.StatusActive}
+	if !account.EmailVerified {
+		user.Status = model.StatusUnverified
+	}
+
+	profile, err := svc.store.Profiles.FindByUserID(ctx, account.ID)
+	switch {
+	case err == nil:
+		user.DisplayName = profile.DisplayName
+	case !errors.Is(err, repo.ErrNotFound):
+		return nil, err
+	}
+
+	deletion, err := svc.store.Deletions.FindByUserID(ctx, account.ID)
+	switch {
+	case err == nil:
+		user.Status = model.StatusPendingDeletion
+		user.DeletionScheduledFor = &deletion.ScheduledFor
+	case !errors.Is(err, repo.ErrNotFound):
+		return nil, err
+	}
+	return user, nil
+}
+
+func userSortKey(user *UserSummary, by model.UserSort) string {
+	switch by {
+	case model.SortEmail:
+		return strings.ToLower(user.Email)
+	case model.SortName:
+		return strings.ToLower(user.DisplayName)
+	default:
+		return user.CreatedAt.UTC().Format(sortKeyTimeLayout)
+	}
+}
+
+func encodeUserCursor(c userCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeUserCursor(s string) (*userCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c userCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
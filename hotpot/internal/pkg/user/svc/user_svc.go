@@ -2,19 +2,158 @@ package svc
 
 import (
 	"context"
+	"errors"
+	"hotpot/internal/core/cfg"
+	"hotpot/internal/core/utils/blob"
+	"hotpot/internal/core/utils/mailer"
+	"hotpot/internal/core/utils/userdata"
+	authmodel "hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/user/model"
+	"hotpot/internal/pkg/user/repo"
 	"log/slog"
+	"time"
 )
 
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrInvalidTimezone  = errors.New("unknown timezone")
+	ErrInvalidBirthDate = errors.New("birth date must be in the past and within 120 years")
+)
+
+// maxAge bounds birth dates to catch typos such as 1099 instead of 1999.
+const maxAge = 120
+
+// AuthService is what the user module needs from the auth module, which
+// owns accounts and the audit log.
+type AuthService interface {
+	AccountExists(ctx context.Context, id string) (bool, error)
+	AccountEmail(ctx context.Context, id string) (string, error)
+	SearchAccounts(ctx context.Context, q authmodel.AccountQuery) ([]authmodel.AccountSummary, error)
+	// RecordAudit attributes the event to the caller attached by mw.RequestContext.
+	RecordAudit(
+		ctx context.Context,
+		eventType authmodel.AuditEventType,
+		subjectID string,
+		outcome authmodel.AuditOutcome,
+		details map[string]string,
+	)
+}
+
 type UserSvc struct {
 	logger *slog.Logger
+	cfg    *cfg.Config
+	store  *repo.Store
+	auth   AuthService
+	mailer mailer.Mailer
+	// blobs keeps uploaded pictures.
+	blobs blob.BlobStore
+	// userData collects the data of every module for exports.
+	userData *userdata.Registry
 }
 
-func NewUserService(logger *slog.Logger) *UserSvc {
+func NewUserService(
+	logger *slog.Logger,
+	config *cfg.Config,
+	store *repo.Store,
+	auth AuthService,
+	mail mailer.Mailer,
+	blobs blob.BlobStore,
+	userData *userdata.Registry,
+) *UserSvc {
 	return &UserSvc{
-		logger: logger,
+		logger:   logger,
+		cfg:      config,
+		store:    store,
+		auth:     auth,
+		mailer:   mail,
+		blobs:    blobs,
+		userData: userData,
 	}
 }
 
 func (svc *UserSvc) Ping(_ context.Context) (bool, error) {
 	return true, nil
 }
+
+// ProfileUpdate lists the profile fields to change. Nil fields are left as they are.
+type ProfileUpdate struct {
+	DisplayName *string
+	BirthDate   *time.Time
+	Sex         *model.Sex
+	HeightCm    *float64
+	Units       *model.Units
+	Activity    *model.ActivityLevel
+	Timezone    *string
+	AvatarURL   *string
+}
+
+// Profile returns the profile of the user. Users who never saved one get
+// the defaults, as long as their account exists.
+func (svc *UserSvc) Profile(ctx context.Context, userID string) (*model.Profile, error) {
+	profile, err := svc.store.Profiles.FindByUserID(ctx, userID)
+	if err == nil {
+		return profile, nil
+	}
+	if !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	exists, err := svc.auth.AccountExists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	return model.NewProfile(userID), nil
+}
+
+// UpdateProfile applies the update to the user's profile and saves it.
+func (svc *UserSvc) UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) (*model.Profile, error) {
+	profile, err := svc.Profile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if update.Timezone != nil {
+		if _, err := time.LoadLocation(*update.Timezone); err != nil || *update.Timezone == "" || *update.Timezone == "Local" {
+			return nil, ErrInvalidTimezone
+		}
+		profile.Timezone = *update.Timezone
+	}
+	if update.BirthDate != nil {
+		b := update.BirthDate.UTC().Truncate(24 * time.Hour)
+		if b.After(now) || b.Before(now.AddDate(-maxAge, 0, 0)) {
+			return nil, ErrInvalidBirthDate
+		}
+		profile.BirthDate = &b
+	}
+	if update.DisplayName != nil {
+		profile.DisplayName = *update.DisplayName
+	}
+	if update.Sex != nil {
+		profile.Sex = *update.Sex
+	}
+	if update.HeightCm != nil {
+		h := *update.HeightCm
+		profile.HeightCm = &h
+	}
+	if update.Units != nil {
+		profile.Units = *update.Units
+	}
+	if update.Activity != nil {
+		profile.Activity = *update.Activity
+	}
+	var replaced map[int]string
+	if update.AvatarURL != nil {
+		profile.AvatarURL = *update.AvatarURL
+		replaced, profile.AvatarKeys = profile.AvatarKeys, nil
+	}
+
+	if err := svc.saveProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+	svc.deleteAvatar(ctx, replaced)
+	return profile, nil
+}
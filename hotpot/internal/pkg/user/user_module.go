@@ -1,31 +1,78 @@
 package user
 
 import (
+	"context"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"hotpot/internal/core/cfg"
+	"hotpot/internal/core/utils/blob"
+	"hotpot/internal/core/utils/mailer"
+	"hotpot/internal/core/utils/servers/scheduler"
+	"hotpot/internal/core/utils/userdata"
+	"hotpot/internal/pkg/auth/model"
+	"hotpot/internal/pkg/auth/mw"
 	"hotpot/internal/pkg/user/ctrl"
+	"hotpot/internal/pkg/user/repo"
 	"hotpot/internal/pkg/user/svc"
 	"log/slog"
+	"time"
 )
 
 type Module struct {
 	Name    string
 	Version string
 
-	logger         *slog.Logger
+	logger        *slog.Logger
+	authMw        *mw.AuthMw
+	deletionEvery time.Duration
+	// serveFiles serves uploaded files when they are kept on local disk.
+	serveFiles     fiber.Handler
 	UserController *ctrl.UserCtrl
+	// Service is shared with modules that need profiles or energy needs.
+	Service *svc.UserSvc
 }
 
-func New(logger *slog.Logger) *Module {
+func New(
+	logger *slog.Logger,
+	config *cfg.Config,
+	authMw *mw.AuthMw,
+	auth svc.AuthService,
+	mail mailer.Mailer,
+	userData *userdata.Registry,
+) (*Module, error) {
+	blobs, err := blob.New(blob.Config{
+		Type:        blob.Type(config.BlobStoreType),
+		Dir:         config.BlobDir,
+		PublicURL:   config.BlobPublicURL,
+		URLSecret:   config.BlobURLSecret,
+		S3Endpoint:  config.S3Endpoint,
+		S3Region:    config.S3Region,
+		S3Bucket:    config.S3Bucket,
+		S3AccessKey: config.S3AccessKey,
+		S3SecretKey: config.S3SecretKey,
+		S3PathStyle: config.S3PathStyle,
+	})
+	if err != nil {
+		// Uploads would otherwise land somewhere other than configured, or fail
+		// on every request.
+		return nil, fmt.Errorf("create blob store: %w", err)
+	}
+
+	userSvc := svc.NewUserService(logger, config, repo.NewMemoryStore(), auth, mail, blobs, userData)
+
 	mod := &Module{
-		Name:    "user-module",
-		Version: "v1",
-		logger:  logger,
-		UserController: ctrl.NewUserController(
-			logger,
-			svc.NewUserService(logger),
-		),
+		Name:           "user-module",
+		Version:        "v1",
+		logger:         logger,
+		authMw:         authMw,
+		deletionEvery:  config.AccountDeletionInterval,
+		UserController: ctrl.NewUserController(logger, userSvc),
+		Service:        userSvc,
 	}
-	return mod
+	if local, ok := blobs.(*blob.LocalStore); ok {
+		mod.serveFiles = local.Handler()
+	}
+	return mod, nil
 }
 
 func (m *Module) InitHTTPRoutes(r fiber.Router) {
@@ -35,4 +82,79 @@ func (m *Module) InitHTTPRoutes(r fiber.Router) {
 
 	modGroup := root.Group("/user")
 	modGroup.Get("/ping", m.UserController.Ping)
+
+	modGroup.Get("/me", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeProfileRead), m.UserController.Me)
+	modGroup.Patch("/me", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeProfileWrite), m.UserController.UpdateMe)
+	modGroup.Put("/me/avatar", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeProfileWrite), m.UserController.UploadAvatar)
+	modGroup.Get("/me/energy", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeProfileRead), m.UserController.Energy)
+
+	modGroup.Get("/me/preferences", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeProfileRead), m.UserController.Preferences)
+	modGroup.Put("/me/preferences", m.authMw.Authenticate, m.authMw.RequireScope(model.ScopeProfileWrite), m.UserController.SetPreferences)
+
+	metricGroup := modGroup.Group("/me/metrics", m.authMw.Authenticate)
+	metricGroup.Post("/", m.authMw.RequireScope(model.ScopeProfileWrite), m.UserController.CreateMeasurement)
+	metricGroup.Get("/", m.authMw.RequireScope(model.ScopeProfileRead), m.UserController.ListMeasurements)
+	metricGroup.Delete("/:id", m.authMw.RequireScope(model.ScopeProfileWrite), m.UserController.DeleteMeasurement)
+
+	// Deleting the account is not available to API keys, and neither is cancelling it.
+	modGroup.Delete("/me", m.authMw.Authenticate, m.authMw.RequireSession, m.UserController.DeleteMe)
+	deletionGroup := modGroup.Group("/me/deletion", m.authMw.Authenticate, m.authMw.RequireSession)
+	deletionGroup.Get("/", m.UserController.Deletion)
+	deletionGroup.Delete("/", m.UserController.CancelDeletion)
+
+	// Exports include other modules' data, so they are not available to API keys.
+	exportGroup := modGroup.Group("/me/export", m.authMw.Authenticate, m.authMw.RequireSession, m.authMw.RequireVerifiedEmail)
+	exportGroup.Post("/", m.UserController.StartExport)
+	exportGroup.Get("/:id", m.UserController.DownloadExport)
+
+	// Clients see and answer invitations, and may end a relationship at any time.
+	coachGroup := modGroup.Group("/me/coaches", m.authMw.Authenticate, m.authMw.RequireSession, m.authMw.RequireVerifiedEmail)
+	coachGroup.Get("/", m.UserController.ListCoaches)
+	coachGroup.Post("/", m.UserController.AcceptInvitation)
+	coachGroup.Get("/invitations", m.UserController.ReceivedInvitations)
+	coachGroup.Delete("/invitations/:id", m.UserController.DeclineInvitation)
+	coachGroup.Delete("/:id", m.UserController.EndCoach)
+
+	// Dietitians invite clients and work with the data their clients share.
+	clientGroup := modGroup.Group("/clients",
+		m.authMw.Authenticate, m.authMw.RequireSession, m.authMw.RequireVerifiedEmail, m.authMw.RequirePermission(model.PermManageClients))
+	clientGroup.Get("/", m.UserController.ListClients)
+	clientGroup.Post("/invitations", m.UserController.InviteClient)
+	clientGroup.Get("/invitations", m.UserController.ListInvitations)
+	clientGroup.Delete("/invitations/:id", m.UserController.CancelInvitation)
+	clientGroup.Delete("/:id", m.UserController.EndClient)
+	clientGroup.Get("/:id/profile", m.UserController.ClientProfile)
+	clientGroup.Get("/:id/metrics", m.UserController.ClientMeasurements)
+	clientGroup.Post("/:id/metrics", m.UserController.CreateClientMeasurement)
+
+	// Signed links to files in the local blob store point here.
+	if m.serveFiles != nil {
+		modGroup.Get("/files/*", m.serveFiles)
+	}
+
+	// Admin routes are registered last so that /:id does not shadow the routes above.
+	modGroup.Get("/", m.authMw.Authenticate, m.authMw.RequireSession, m.authMw.RequireVerifiedEmail,
+		m.authMw.RequirePermission(model.PermManageUsers), m.UserController.ListUsers)
+	modGroup.Get("/:id", m.authMw.Authenticate, m.authMw.RequireSession, m.authMw.RequireVerifiedEmail,
+		m.authMw.RequirePermission(model.PermManageUsers), m.UserController.Get)
+}
+
+// ExportUserData adds the user's profile, preferences, body metrics and
+// coaching relationships to data exports.
+func (m *Module) ExportUserData(ctx context.Context, userID string) ([]userdata.Dataset, error) {
+	return m.Service.ExportUserData(ctx, userID)
+}
+
+// EraseUserData deletes the user's profile, preferences, body metrics,
+// coaching relationships and exports.
+func (m *Module) EraseUserData(ctx context.Context, userID string) error {
+	return m.Service.EraseUserData(ctx, userID)
+}
+
+func (m *Module) InitJobs(s *scheduler.Scheduler) {
+	s.Add(scheduler.Job{
+		Name:     "account-deletion",
+		Interval: m.deletionEvery,
+		Run:      m.Service.ProcessDueDeletions,
+	})
 }